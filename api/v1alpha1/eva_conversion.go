@@ -0,0 +1,268 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1beta1"
+)
+
+// fooAnnotation keeps spec.foo across a round trip through v1beta1, which has no such field.
+const fooAnnotation = "geofront.nerv.com/v1alpha1-foo"
+
+// ConvertTo converts this Eva (v1alpha1) to the Hub version (v1beta1).
+func (src *Eva) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Eva)
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	if src.Spec.Foo != nil {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[fooAnnotation] = *src.Spec.Foo
+	}
+
+	dst.Spec.Container = v1beta1.EvaContainerSpec{
+		Image:              src.Spec.Image,
+		Command:            src.Spec.Command,
+		Args:               src.Spec.Args,
+		Env:                src.Spec.Env,
+		EnvFrom:            src.Spec.EnvFrom,
+		WorkingDir:         src.Spec.WorkingDir,
+		Ports:              src.Spec.Ports,
+		VolumeMounts:       src.Spec.VolumeMounts,
+		ImagePullSecret:    src.Spec.ImagePullSecret,
+		ResolveImageDigest: src.Spec.ResolveImageDigest,
+		Resources:          src.Spec.Resources,
+		Probes:             (*v1beta1.EvaProbes)(src.Spec.Probes),
+	}
+	dst.Spec.Scheduling = v1beta1.EvaSchedulingSpec{
+		Replicas:                  src.Spec.Replicas,
+		Paused:                    src.Spec.Paused,
+		BackoffLimit:              src.Spec.BackoffLimit,
+		ActiveDeadlineSeconds:     src.Spec.ActiveDeadlineSeconds,
+		TTLSecondsAfterFinished:   src.Spec.TTLSecondsAfterFinished,
+		Parallelism:               src.Spec.Parallelism,
+		Completions:               src.Spec.Completions,
+		CompletionMode:            src.Spec.CompletionMode,
+		SuccessPolicy:             src.Spec.SuccessPolicy,
+		BackoffLimitPerIndex:      src.Spec.BackoffLimitPerIndex,
+		NodeSelector:              src.Spec.Scheduling.NodeSelector,
+		Affinity:                  src.Spec.Scheduling.Affinity,
+		Tolerations:               src.Spec.Scheduling.Tolerations,
+		TopologySpreadConstraints: src.Spec.Scheduling.TopologySpreadConstraints,
+		PriorityClassName:         src.Spec.Scheduling.PriorityClassName,
+		RuntimeClassName:          src.Spec.Scheduling.RuntimeClassName,
+	}
+	dst.Spec.Exposure = v1beta1.EvaExposureSpec{
+		Port: src.Spec.Port,
+	}
+	dst.Spec.Mode = v1beta1.EvaMode(src.Spec.Mode)
+	dst.Spec.UpdateStrategy = v1beta1.EvaUpdateStrategy(src.Spec.UpdateStrategy)
+	dst.Spec.DeletionPolicy = v1beta1.EvaDeletionPolicy(src.Spec.DeletionPolicy)
+	dst.Spec.DeletionTimeout = src.Spec.DeletionTimeout
+	dst.Spec.FailurePolicy = (*v1beta1.EvaFailurePolicy)(src.Spec.FailurePolicy)
+	dst.Spec.RetryPolicy = (*v1beta1.EvaRetryPolicy)(src.Spec.RetryPolicy)
+	dst.Spec.Schedule = (*v1beta1.EvaSchedule)(src.Spec.Schedule)
+	dst.Spec.SecurityContext = (*v1beta1.EvaSecurityContext)(src.Spec.SecurityContext)
+	dst.Spec.ServiceAccount = (*v1beta1.EvaServiceAccount)(src.Spec.ServiceAccount)
+	dst.Spec.Permissions = src.Spec.Permissions
+	dst.Spec.InitContainers = src.Spec.InitContainers
+	dst.Spec.Sidecars = src.Spec.Sidecars
+	dst.Spec.Volumes = src.Spec.Volumes
+	if src.Spec.Storage != nil {
+		dst.Spec.Storage = &v1beta1.EvaStorage{
+			Size:             src.Spec.Storage.Size,
+			StorageClassName: src.Spec.Storage.StorageClassName,
+			AccessModes:      src.Spec.Storage.AccessModes,
+			MountPath:        src.Spec.Storage.MountPath,
+			ReclaimPolicy:    v1beta1.EvaStorageReclaimPolicy(src.Spec.Storage.ReclaimPolicy),
+		}
+	}
+	dst.Spec.PodFailurePolicy = src.Spec.PodFailurePolicy
+	dst.Spec.Color = src.Spec.Color
+	dst.Spec.Pilot = src.Spec.Pilot
+
+	dst.Status = v1beta1.EvaStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Phase:              v1beta1.EvaPhase(src.Status.Phase),
+		SpecHash:           src.Status.SpecHash,
+		JobGeneration:      src.Status.JobGeneration,
+		ResolvedImage:      src.Status.ResolvedImage,
+		StartTime:          src.Status.StartTime,
+		CompletionTime:     src.Status.CompletionTime,
+		JobRef:             src.Status.JobRef,
+		PodRefs:            src.Status.PodRefs,
+		Attempts:           src.Status.Attempts,
+		Progress:           src.Status.Progress,
+		CompletedIndexes:   src.Status.CompletedIndexes,
+		FailedIndexes:      src.Status.FailedIndexes,
+		CurrentAttempt:     src.Status.CurrentAttempt,
+		LastScheduleTime:   src.Status.LastScheduleTime,
+		LastSuccessfulTime: src.Status.LastSuccessfulTime,
+		Conditions:         src.Status.Conditions,
+	}
+	for _, container := range src.Status.Containers {
+		dst.Status.Containers = append(dst.Status.Containers, v1beta1.EvaContainerStatus{
+			Name:         container.Name,
+			Pod:          container.Pod,
+			ExitCode:     container.ExitCode,
+			Reason:       container.Reason,
+			Message:      container.Message,
+			Type:         v1beta1.EvaContainerType(container.Type),
+			State:        container.State,
+			Ready:        container.Ready,
+			RestartCount: container.RestartCount,
+		})
+	}
+	for _, secret := range src.Spec.ImagePullSecrets {
+		dst.Spec.Container.ImagePullSecrets = append(dst.Spec.Container.ImagePullSecrets, v1beta1.EvaImagePullSecret(secret))
+	}
+	for _, attempt := range src.Status.AttemptHistory {
+		dst.Status.AttemptHistory = append(dst.Status.AttemptHistory, v1beta1.EvaAttemptStatus(attempt))
+	}
+	for _, run := range src.Status.RecentRuns {
+		dst.Status.RecentRuns = append(dst.Status.RecentRuns, v1beta1.EvaRunSummary{
+			JobName:        run.JobName,
+			Phase:          v1beta1.EvaPhase(run.Phase),
+			StartTime:      run.StartTime,
+			CompletionTime: run.CompletionTime,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the Hub version (v1beta1) to this Eva (v1alpha1).
+func (dst *Eva) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Eva)
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec.Foo = nil
+	if foo, ok := dst.Annotations[fooAnnotation]; ok {
+		dst.Spec.Foo = &foo
+		delete(dst.Annotations, fooAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.Image = src.Spec.Container.Image
+	dst.Spec.Command = src.Spec.Container.Command
+	dst.Spec.Args = src.Spec.Container.Args
+	dst.Spec.Env = src.Spec.Container.Env
+	dst.Spec.EnvFrom = src.Spec.Container.EnvFrom
+	dst.Spec.WorkingDir = src.Spec.Container.WorkingDir
+	dst.Spec.Ports = src.Spec.Container.Ports
+	dst.Spec.VolumeMounts = src.Spec.Container.VolumeMounts
+	dst.Spec.ImagePullSecret = src.Spec.Container.ImagePullSecret
+	dst.Spec.ResolveImageDigest = src.Spec.Container.ResolveImageDigest
+	dst.Spec.Resources = src.Spec.Container.Resources
+	dst.Spec.Probes = (*EvaProbes)(src.Spec.Container.Probes)
+	dst.Spec.Replicas = src.Spec.Scheduling.Replicas
+	dst.Spec.Paused = src.Spec.Scheduling.Paused
+	dst.Spec.BackoffLimit = src.Spec.Scheduling.BackoffLimit
+	dst.Spec.ActiveDeadlineSeconds = src.Spec.Scheduling.ActiveDeadlineSeconds
+	dst.Spec.TTLSecondsAfterFinished = src.Spec.Scheduling.TTLSecondsAfterFinished
+	dst.Spec.Parallelism = src.Spec.Scheduling.Parallelism
+	dst.Spec.Completions = src.Spec.Scheduling.Completions
+	dst.Spec.CompletionMode = src.Spec.Scheduling.CompletionMode
+	dst.Spec.SuccessPolicy = src.Spec.Scheduling.SuccessPolicy
+	dst.Spec.BackoffLimitPerIndex = src.Spec.Scheduling.BackoffLimitPerIndex
+	dst.Spec.Scheduling = EvaSchedulingSpec{
+		NodeSelector:              src.Spec.Scheduling.NodeSelector,
+		Affinity:                  src.Spec.Scheduling.Affinity,
+		Tolerations:               src.Spec.Scheduling.Tolerations,
+		TopologySpreadConstraints: src.Spec.Scheduling.TopologySpreadConstraints,
+		PriorityClassName:         src.Spec.Scheduling.PriorityClassName,
+		RuntimeClassName:          src.Spec.Scheduling.RuntimeClassName,
+	}
+	dst.Spec.Port = src.Spec.Exposure.Port
+	dst.Spec.Mode = EvaMode(src.Spec.Mode)
+	dst.Spec.UpdateStrategy = EvaUpdateStrategy(src.Spec.UpdateStrategy)
+	dst.Spec.DeletionPolicy = EvaDeletionPolicy(src.Spec.DeletionPolicy)
+	dst.Spec.DeletionTimeout = src.Spec.DeletionTimeout
+	dst.Spec.FailurePolicy = (*EvaFailurePolicy)(src.Spec.FailurePolicy)
+	dst.Spec.RetryPolicy = (*EvaRetryPolicy)(src.Spec.RetryPolicy)
+	dst.Spec.Schedule = (*EvaSchedule)(src.Spec.Schedule)
+	dst.Spec.SecurityContext = (*EvaSecurityContext)(src.Spec.SecurityContext)
+	dst.Spec.ServiceAccount = (*EvaServiceAccount)(src.Spec.ServiceAccount)
+	dst.Spec.Permissions = src.Spec.Permissions
+	dst.Spec.InitContainers = src.Spec.InitContainers
+	dst.Spec.Sidecars = src.Spec.Sidecars
+	dst.Spec.Volumes = src.Spec.Volumes
+	if src.Spec.Storage != nil {
+		dst.Spec.Storage = &EvaStorage{
+			Size:             src.Spec.Storage.Size,
+			StorageClassName: src.Spec.Storage.StorageClassName,
+			AccessModes:      src.Spec.Storage.AccessModes,
+			MountPath:        src.Spec.Storage.MountPath,
+			ReclaimPolicy:    EvaStorageReclaimPolicy(src.Spec.Storage.ReclaimPolicy),
+		}
+	}
+	dst.Spec.PodFailurePolicy = src.Spec.PodFailurePolicy
+	dst.Spec.Color = src.Spec.Color
+	dst.Spec.Pilot = src.Spec.Pilot
+
+	dst.Status = EvaStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Phase:              EvaPhase(src.Status.Phase),
+		SpecHash:           src.Status.SpecHash,
+		JobGeneration:      src.Status.JobGeneration,
+		ResolvedImage:      src.Status.ResolvedImage,
+		StartTime:          src.Status.StartTime,
+		CompletionTime:     src.Status.CompletionTime,
+		JobRef:             src.Status.JobRef,
+		PodRefs:            src.Status.PodRefs,
+		Attempts:           src.Status.Attempts,
+		Progress:           src.Status.Progress,
+		CompletedIndexes:   src.Status.CompletedIndexes,
+		FailedIndexes:      src.Status.FailedIndexes,
+		CurrentAttempt:     src.Status.CurrentAttempt,
+		LastScheduleTime:   src.Status.LastScheduleTime,
+		LastSuccessfulTime: src.Status.LastSuccessfulTime,
+		Conditions:         src.Status.Conditions,
+	}
+	for _, container := range src.Status.Containers {
+		dst.Status.Containers = append(dst.Status.Containers, EvaContainerStatus{
+			Name:         container.Name,
+			Pod:          container.Pod,
+			ExitCode:     container.ExitCode,
+			Reason:       container.Reason,
+			Message:      container.Message,
+			Type:         EvaContainerType(container.Type),
+			State:        container.State,
+			Ready:        container.Ready,
+			RestartCount: container.RestartCount,
+		})
+	}
+	for _, secret := range src.Spec.Container.ImagePullSecrets {
+		dst.Spec.ImagePullSecrets = append(dst.Spec.ImagePullSecrets, EvaImagePullSecret(secret))
+	}
+	for _, attempt := range src.Status.AttemptHistory {
+		dst.Status.AttemptHistory = append(dst.Status.AttemptHistory, EvaAttemptStatus(attempt))
+	}
+	for _, run := range src.Status.RecentRuns {
+		dst.Status.RecentRuns = append(dst.Status.RecentRuns, EvaRunSummary{
+			JobName:        run.JobName,
+			Phase:          EvaPhase(run.Phase),
+			StartTime:      run.StartTime,
+			CompletionTime: run.CompletionTime,
+		})
+	}
+	return nil
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,13 +31,78 @@ import (
 type EvaPhase string
 
 const (
-	EvaPhasePending   EvaPhase = "Pending"
-	EvaPhaseRunning   EvaPhase = "Running"
-	EvaPhaseSucceeded EvaPhase = "Succeeded"
-	EvaPhaseFailed    EvaPhase = "Failed"
-	EvaPhaseUnknown   EvaPhase = "Unknown"
+	EvaPhasePending     EvaPhase = "Pending"
+	EvaPhaseRunning     EvaPhase = "Running"
+	EvaPhaseSucceeded   EvaPhase = "Succeeded"
+	EvaPhaseFailed      EvaPhase = "Failed"
+	EvaPhasePaused      EvaPhase = "Paused"
+	EvaPhaseTerminating EvaPhase = "Terminating"
+	EvaPhaseUnknown     EvaPhase = "Unknown"
 )
 
+// EvaMode defines how an Eva unit is run
+// +kubebuilder:validation:Enum=Job;Deployment;CronJob
+type EvaMode string
+
+const (
+	// EvaModeJob runs the unit to completion as a batch Job
+	EvaModeJob EvaMode = "Job"
+	// EvaModeDeployment runs the unit as a long-lived Deployment fronted by a Service
+	EvaModeDeployment EvaMode = "Deployment"
+	// EvaModeCronJob runs the unit as a Job on a schedule through a CronJob
+	EvaModeCronJob EvaMode = "CronJob"
+)
+
+// EvaUpdateStrategy defines what happens to the current Job when the Eva spec changes
+// +kubebuilder:validation:Enum=Ignore;Replace;RecreateWhenFinished
+type EvaUpdateStrategy string
+
+const (
+	// EvaUpdateStrategyIgnore keeps the current Job and never reruns it for spec changes
+	EvaUpdateStrategyIgnore EvaUpdateStrategy = "Ignore"
+	// EvaUpdateStrategyReplace deletes the current Job, even while it runs, and starts a new one
+	EvaUpdateStrategyReplace EvaUpdateStrategy = "Replace"
+	// EvaUpdateStrategyRecreateWhenFinished waits for the current Job to finish before starting a new one
+	EvaUpdateStrategyRecreateWhenFinished EvaUpdateStrategy = "RecreateWhenFinished"
+)
+
+// EvaDeletionPolicy defines what happens to an Eva's workload when the Eva is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type EvaDeletionPolicy string
+
+const (
+	// EvaDeletionPolicyDelete stops the workload, waits for its pods to terminate and deletes it
+	EvaDeletionPolicyDelete EvaDeletionPolicy = "Delete"
+	// EvaDeletionPolicyOrphan leaves the workload running and detaches it from the Eva
+	EvaDeletionPolicyOrphan EvaDeletionPolicy = "Orphan"
+	// EvaDeletionPolicyRetain stops the workload and keeps the stopped objects, detached from the Eva
+	EvaDeletionPolicyRetain EvaDeletionPolicy = "Retain"
+)
+
+// EvaStorageReclaimPolicy defines what happens to an Eva's PersistentVolumeClaim when the Eva is deleted
+// +kubebuilder:validation:Enum=Delete;Retain
+type EvaStorageReclaimPolicy string
+
+const (
+	// EvaStorageReclaimPolicyDelete deletes the claim along with the Eva
+	EvaStorageReclaimPolicyDelete EvaStorageReclaimPolicy = "Delete"
+	// EvaStorageReclaimPolicyRetain keeps the claim, detached from the Eva
+	EvaStorageReclaimPolicyRetain EvaStorageReclaimPolicy = "Retain"
+)
+
+// EvaStorageVolumeName is the name of the pod volume backed by the Eva's PersistentVolumeClaim
+const EvaStorageVolumeName = "eva-storage"
+
+// EvaTmpVolumeName is the name of the emptyDir volume mounted at /tmp when the
+// container's root filesystem is read-only
+const EvaTmpVolumeName = "eva-tmp"
+
+// EvaRestrictedDefaultsAnnotation set to "true" gives the Eva's pods the
+// restricted Pod Security defaults. The controller sets it on Evas it has not
+// run yet; Evas that ran before the defaults were introduced go without them
+// unless it is set by hand.
+const EvaRestrictedDefaultsAnnotation = "geofront.nerv.com/restricted-defaults"
+
 type EvaConditionType string
 
 const (
@@ -41,9 +110,188 @@ const (
 	EvaConditionProgressing EvaConditionType = "Progressing"
 	EvaConditionDegraded    EvaConditionType = "Degraded"
 	EvaConditionFailed      EvaConditionType = "Failed"
+	EvaConditionPaused      EvaConditionType = "Paused"
+	// EvaConditionReady is True when the Eva is available and neither degraded
+	// nor failed, as expected by kstatus and kubectl wait
+	EvaConditionReady EvaConditionType = "Ready"
+	// EvaConditionPodSecurityAdmitted is False when the Pod Security level the
+	// namespace enforces would reject the Eva's pods
+	EvaConditionPodSecurityAdmitted EvaConditionType = "PodSecurityAdmitted"
+	// EvaConditionPermissionsGranted is False when the controller could not
+	// create the ServiceAccount, Role or RoleBinding of the Eva
+	EvaConditionPermissionsGranted EvaConditionType = "PermissionsGranted"
+	// EvaConditionPullSecretMissing is True when an image pull secret of the
+	// Eva does not exist or may not be shared, before its pods fail to pull
+	EvaConditionPullSecretMissing EvaConditionType = "PullSecretMissing"
 )
 
+// EvaFailurePolicy defines how long transient pod failures are tolerated
+type EvaFailurePolicy struct {
+	// imagePullGracePeriod is how long a pod may fail to pull its image
+	// before the Eva is marked failed. The Eva stays Pending and Degraded
+	// in the meantime.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="imagePullGracePeriod must not be negative"
+	// +optional
+	ImagePullGracePeriod *metav1.Duration `json:"imagePullGracePeriod,omitempty"`
+}
+
+// EvaStorage defines the PersistentVolumeClaim provisioned for an Eva
+type EvaStorage struct {
+	// size is the requested capacity of the claim. It can be increased but not decreased.
+	Size resource.Quantity `json:"size"`
+	// storageClassName is the StorageClass the claim is provisioned from.
+	// The cluster default is used when unset.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// accessModes are the ways the volume can be mounted.
+	// +kubebuilder:default={"ReadWriteOnce"}
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// mountPath is where the volume is mounted in the container.
+	// +kubebuilder:validation:MinLength=1
+	MountPath string `json:"mountPath"`
+	// reclaimPolicy controls whether the claim is deleted along with the Eva.
+	// The claim is always kept when the deletion policy is Orphan, since the
+	// orphaned workload may still mount it.
+	// +kubebuilder:default=Delete
+	// +optional
+	ReclaimPolicy EvaStorageReclaimPolicy `json:"reclaimPolicy,omitempty"`
+}
+
+// EvaSchedule defines when a CronJob-mode Eva runs and how its runs are kept
+type EvaSchedule struct {
+	// cron is the schedule in cron syntax, such as "0 3 * * *".
+	// +kubebuilder:validation:MinLength=1
+	Cron string `json:"cron"`
+	// timeZone is the IANA time zone the schedule is interpreted in, such as
+	// "Asia/Tokyo". The controller manager's time zone is used when unset.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+	// concurrencyPolicy decides what happens when a run is due while the previous one is still running.
+	// +kubebuilder:default=Forbid
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +optional
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// startingDeadlineSeconds is how late a missed run may still be started.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// successfulJobsHistoryLimit is the number of succeeded Jobs kept.
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// failedJobsHistoryLimit is the number of failed Jobs kept.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// EvaRetryPolicy defines how failed runs of a Job-mode Eva are retried
+type EvaRetryPolicy struct {
+	// maxAttempts is the number of Jobs run for one spec, counting the first.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+	// backoff is the delay before the second attempt. It doubles with each further attempt.
+	// +kubebuilder:default="10s"
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+	// maxBackoff caps the delay between attempts.
+	// +kubebuilder:default="10m"
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+	// retryOnReasons limits retries to failures with one of these reasons,
+	// such as ImagePullBackOff, OOMKilled, DeadlineExceeded or PodEvicted.
+	// Every failure is retried when empty.
+	// +optional
+	RetryOnReasons []string `json:"retryOnReasons,omitempty"`
+	// noRetryOnExitCodes lists container exit codes that make a failure final.
+	// +optional
+	NoRetryOnExitCodes []int32 `json:"noRetryOnExitCodes,omitempty"`
+}
+
+// EvaSchedulingSpec defines where the pods of an Eva run
+type EvaSchedulingSpec struct {
+	// nodeSelector restricts the pods to nodes carrying all of these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// affinity holds the node, pod and pod anti-affinity rules of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// tolerations let the pods run on nodes with matching taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// topologySpreadConstraints spread the pods across topology domains such as zones.
+	// A constraint without a label selector selects the pods of this Eva.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// priorityClassName names the PriorityClass of the pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// runtimeClassName names the RuntimeClass the pods run with.
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+}
+
+// EvaSecurityContext overrides the restricted Pod Security defaults of an
+// Eva's pods. Fields left unset keep their default. Only Evas carrying the
+// geofront.nerv.com/restricted-defaults annotation get the defaults.
+type EvaSecurityContext struct {
+	// pod overrides the pod-level defaults, which run as non-root with the
+	// RuntimeDefault seccomp profile.
+	// +optional
+	Pod *corev1.PodSecurityContext `json:"pod,omitempty"`
+	// container overrides the container-level defaults, which forbid privilege
+	// escalation and drop all capabilities. The root filesystem stays writable
+	// unless readOnlyRootFilesystem is set, which mounts a writable /tmp.
+	// +optional
+	Container *corev1.SecurityContext `json:"container,omitempty"`
+}
+
+// EvaServiceAccount selects the ServiceAccount an Eva's pods run as
+// +kubebuilder:validation:XValidation:rule="has(self.name) != (has(self.create) && self.create)",message="exactly one of name or create must be set"
+type EvaServiceAccount struct {
+	// name references an existing ServiceAccount in the Eva's namespace.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// create has the controller create a ServiceAccount owned by the Eva,
+	// bound to a Role granting spec.permissions.
+	// +optional
+	Create bool `json:"create,omitempty"`
+}
+
+// EvaImagePullSecret references a Secret holding registry credentials
+type EvaImagePullSecret struct {
+	// name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// shared copies the Secret from the operator's shared pull secret
+	// namespace into the Eva's namespace, owned by the Eva and kept in sync,
+	// instead of using a Secret of the Eva's namespace. Only Secrets of type
+	// kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg labelled
+	// geofront.nerv.com/shareable=true are copied.
+	// +optional
+	Shared bool `json:"shared,omitempty"`
+}
+
+// EvaProbes defines the health checks of a Deployment-mode Eva's container
+type EvaProbes struct {
+	// liveness restarts the container when it fails.
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// readiness keeps the pod out of the Service and the Eva unavailable until it succeeds.
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// startup holds off the other probes until the container has started.
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+}
+
 // EvaSpec defines the desired state of Eva
+// +kubebuilder:validation:XValidation:rule="(has(self.mode) && self.mode == 'CronJob') == has(self.schedule)",message="schedule must be set in CronJob mode and only in CronJob mode"
 type EvaSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -53,12 +301,249 @@ type EvaSpec struct {
 	Image string `json:"image"`
 	// foo is an example field of Eva. Edit eva_types.go to remove/update
 	// +optional
-	Foo             *string  `json:"foo,omitempty"`
+	Foo *string `json:"foo,omitempty"`
+	// paused suspends the unit's Job or CronJob, or scales its Deployment to zero, until it is unset.
+	// +optional
 	Paused          bool     `json:"paused,omitempty"`
 	ImagePullSecret string   `json:"imagePullSecret,omitempty"`
 	Color           string   `json:"color,omitempty"`
 	Pilot           string   `json:"pilot,omitempty"`
 	Command         []string `json:"command,omitempty"`
+
+	// args are the arguments passed to the command, or to the image entrypoint.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// env sets environment variables in the container, either literally or
+	// from a Secret, ConfigMap, or field of the pod.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// envFrom populates environment variables from whole Secrets or ConfigMaps.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// workingDir is the directory the command runs in.
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+	// ports are the ports the container declares.
+	// +optional
+	Ports []corev1.ContainerPort `json:"ports,omitempty"`
+	// initContainers run to completion, in order, before the main container starts.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// sidecars run alongside the main container as native sidecars. They start
+	// before the init containers and do not hold up the completion of a Job.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+	// volumeMounts mount the volumes into the container.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// volumes are the ConfigMap, Secret, emptyDir and projected volumes of the pod.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// storage provisions a PersistentVolumeClaim owned by the Eva and mounts it into the container.
+	// +optional
+	Storage *EvaStorage `json:"storage,omitempty"`
+
+	// mode selects whether the unit runs as a Job, as a long-lived Deployment,
+	// or as a Job on a schedule.
+	// +kubebuilder:default=Job
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="mode is immutable"
+	// +optional
+	Mode EvaMode `json:"mode,omitempty"`
+	// replicas is the number of pods to run in Deployment mode.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// probes are the liveness, readiness and startup checks of a Deployment-mode unit.
+	// +optional
+	Probes *EvaProbes `json:"probes,omitempty"`
+	// port is the container port exposed in Deployment mode. When set, a
+	// Service fronting the Deployment is created on the same port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// schedule defines when a CronJob-mode unit runs. It is required in CronJob mode.
+	// +optional
+	Schedule *EvaSchedule `json:"schedule,omitempty"`
+	// updateStrategy controls how a Job-mode Eva reacts to spec changes once its Job exists.
+	// +kubebuilder:default=RecreateWhenFinished
+	// +optional
+	UpdateStrategy EvaUpdateStrategy `json:"updateStrategy,omitempty"`
+	// deletionPolicy controls how the unit's workload is torn down when the Eva is deleted.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy EvaDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// deletionTimeout bounds how long teardown waits for pods to terminate
+	// before the finalizer is removed regardless.
+	// +kubebuilder:default="5m"
+	// +optional
+	DeletionTimeout *metav1.Duration `json:"deletionTimeout,omitempty"`
+	// failurePolicy defines how long transient pod failures are tolerated.
+	// +optional
+	FailurePolicy *EvaFailurePolicy `json:"failurePolicy,omitempty"`
+	// retryPolicy controls whether a failed Job is followed by a fresh one.
+	// +optional
+	RetryPolicy *EvaRetryPolicy `json:"retryPolicy,omitempty"`
+	// backoffLimit is the number of retries of the Job's pod before the run is marked failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// activeDeadlineSeconds bounds how long the Job may run before it is failed
+	// with DeadlineExceeded.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// ttlSecondsAfterFinished deletes the Job this long after it finished.
+	// The Eva keeps its final phase.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	// podFailurePolicy decides how the Job treats failed pods, such as
+	// ignoring disruptions or failing fast on specific exit codes.
+	// +optional
+	PodFailurePolicy *batchv1.PodFailurePolicy `json:"podFailurePolicy,omitempty"`
+	// parallelism is the number of pods the Job runs at once. Changing it
+	// scales a running Job in place.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+	// completions is the number of pods that must succeed for the Job to
+	// succeed. When unset, the Job succeeds once any pod succeeded and the
+	// others have finished.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+	// completionMode is NonIndexed, or Indexed to give each pod an index from 0
+	// to completions-1 and a stable hostname behind a headless Service.
+	// +kubebuilder:validation:Enum=NonIndexed;Indexed
+	// +optional
+	CompletionMode batchv1.CompletionMode `json:"completionMode,omitempty"`
+	// successPolicy lets an Indexed Job succeed once some of its indexes did.
+	// +optional
+	SuccessPolicy *batchv1.SuccessPolicy `json:"successPolicy,omitempty"`
+	// backoffLimitPerIndex is the number of retries of each index of an Indexed
+	// Job before that index is marked failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimitPerIndex *int32 `json:"backoffLimitPerIndex,omitempty"`
+	// resources are the compute resources requested by the unit's container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// scheduling constrains the nodes the unit's pods run on.
+	// +optional
+	Scheduling EvaSchedulingSpec `json:"scheduling,omitempty"`
+	// securityContext overrides the restricted Pod Security defaults of the unit's pods.
+	// +optional
+	SecurityContext *EvaSecurityContext `json:"securityContext,omitempty"`
+	// serviceAccount is the ServiceAccount the unit's pods run as, instead of
+	// the namespace's default one.
+	// +optional
+	ServiceAccount *EvaServiceAccount `json:"serviceAccount,omitempty"`
+	// permissions are the rules of the Role bound to a ServiceAccount the
+	// controller creates. They may only grant what the Eva's author holds,
+	// and are only granted while the admission webhook checks that.
+	// +listType=atomic
+	// +optional
+	Permissions []rbacv1.PolicyRule `json:"permissions,omitempty"`
+	// imagePullSecrets are the Secrets used to pull the images, in addition
+	// to imagePullSecret.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ImagePullSecrets []EvaImagePullSecret `json:"imagePullSecrets,omitempty"`
+	// resolveImageDigest runs the Job by the digest the image's tag points to
+	// when its run starts, so that retries and reruns use the same image until
+	// the image changes. The registry is queried with the Eva's pull secrets.
+	// +optional
+	ResolveImageDigest bool `json:"resolveImageDigest,omitempty"`
+}
+
+// EvaContainerType tells the Eva's main container apart from its init and sidecar containers
+// +kubebuilder:validation:Enum=Main;Init;Sidecar
+type EvaContainerType string
+
+const (
+	EvaContainerTypeMain    EvaContainerType = "Main"
+	EvaContainerTypeInit    EvaContainerType = "Init"
+	EvaContainerTypeSidecar EvaContainerType = "Sidecar"
+)
+
+// EvaContainerStatus records the state of a container of the Eva's latest pod
+// and how it last ended
+type EvaContainerStatus struct {
+	// name is the name of the container.
+	Name string `json:"name"`
+	// pod is the name of the pod the container ran in.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// exitCode is the exit code of the container's last termination.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// reason is why the container, or its pod, was terminated, such as
+	// OOMKilled, DeadlineExceeded or Error.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// message is the termination message written by the container.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// type tells the main container apart from init and sidecar containers.
+	// +optional
+	Type EvaContainerType `json:"type,omitempty"`
+	// state is Waiting, Running or Terminated.
+	// +optional
+	State string `json:"state,omitempty"`
+	// ready reports whether the container passes its readiness checks.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+	// restartCount is the number of times the container was restarted.
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+}
+
+// EvaAttemptStatus records how one Job run for an Eva ended
+type EvaAttemptStatus struct {
+	// attempt is the number of the attempt, starting at 1 for each spec.
+	Attempt int32 `json:"attempt"`
+	// jobName is the name of the Job run for the attempt.
+	JobName string `json:"jobName"`
+	// startTime is when the Job started running.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// finishTime is when the attempt succeeded or failed.
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+	// reason is JobSucceeded for a successful attempt, or the failure reason.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// message describes the outcome of the attempt.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// exitCode is the exit code of the first container that failed.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+}
+
+// EvaRunSummary summarises one Job started by the schedule of a CronJob-mode Eva
+type EvaRunSummary struct {
+	// jobName is the name of the Job.
+	JobName string `json:"jobName"`
+	// phase is Running, Succeeded or Failed.
+	Phase EvaPhase `json:"phase"`
+	// startTime is when the Job started running.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// completionTime is when the Job succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // EvaStatus defines the observed state of Eva.
@@ -73,6 +558,61 @@ type EvaStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,1,opt,name=observedGeneration"`
 	// +optional
 	Phase EvaPhase `json:"phase,omitempty"`
+	// specHash is the hash of the pod template the current Job was built from.
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+	// jobGeneration is the Eva generation the current Job was built from.
+	// +optional
+	JobGeneration int64 `json:"jobGeneration,omitempty"`
+	// resolvedImage is the image pinned to a digest that the current Job runs.
+	// +optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+	// startTime is when the current Job started running.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// completionTime is when the current Job succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// jobRef points at the current Job.
+	// +optional
+	JobRef *corev1.ObjectReference `json:"jobRef,omitempty"`
+	// podRefs point at the pods the current Job created, oldest first.
+	// +optional
+	PodRefs []corev1.ObjectReference `json:"podRefs,omitempty"`
+	// attempts is the number of pods the current Job has started.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// containers records how each container of the latest pod ended.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Containers []EvaContainerStatus `json:"containers,omitempty"`
+	// progress counts the completions of the current Job, as "k/N completed".
+	// +optional
+	Progress string `json:"progress,omitempty"`
+	// completedIndexes lists the succeeded indexes of an Indexed Job, such as "0-2,5".
+	// +optional
+	CompletedIndexes string `json:"completedIndexes,omitempty"`
+	// failedIndexes lists the indexes of an Indexed Job that ran out of retries.
+	// +optional
+	FailedIndexes string `json:"failedIndexes,omitempty"`
+	// currentAttempt is the attempt number of the current Job.
+	// +optional
+	CurrentAttempt int32 `json:"currentAttempt,omitempty"`
+	// attemptHistory records the most recent finished attempts, oldest first.
+	// +listType=atomic
+	// +optional
+	AttemptHistory []EvaAttemptStatus `json:"attemptHistory,omitempty"`
+	// lastScheduleTime is when the schedule last started a Job.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// lastSuccessfulTime is when a scheduled Job last succeeded.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// recentRuns summarises the scheduled Jobs still kept, oldest first.
+	// +listType=atomic
+	// +optional
+	RecentRuns []EvaRunSummary `json:"recentRuns,omitempty"`
 
 	// conditions represent the current state of the Eva resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
@@ -81,6 +621,8 @@ type EvaStatus struct {
 	// - "Available": the resource is fully functional
 	// - "Progressing": the resource is being created or updated
 	// - "Degraded": the resource failed to reach or maintain its desired state
+	// - "Failed": the run ended in a terminal failure
+	// - "Ready": the resource is available and neither degraded nor failed
 	//
 	// The status of each condition is one of True, False, or Unknown.
 	// +listType=map
@@ -92,7 +634,10 @@ type EvaStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Progress",type=string,JSONPath=`.status.progress`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // Eva is the Schema for the evas API
 type Eva struct {
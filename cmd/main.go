@@ -19,16 +19,25 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -36,7 +45,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	geofrontv1beta1 "github.com/dayaliuzzo/Smooth-Operator/api/v1beta1"
 	"github.com/dayaliuzzo/Smooth-Operator/internal/controllers/eva"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/migration"
+	webhookv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/internal/webhook/v1alpha1"
+	webhookv1beta1 "github.com/dayaliuzzo/Smooth-Operator/internal/webhook/v1beta1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -48,7 +61,10 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
 	utilruntime.Must(geofrontv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(geofrontv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -62,6 +78,12 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var defaultImagePullSecret string
+	var pullSecretNamespace string
+	var defaultCPURequest, defaultMemoryRequest, defaultCPULimit, defaultMemoryLimit string
+	var defaultBackoffLimit int
+	var migrateStorageVersion bool
+	var archiveTTL time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -79,6 +101,19 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&defaultImagePullSecret, "default-image-pull-secret", "",
+		"The image pull secret set on new Evas that reference none, if it exists in their namespace.")
+	flag.StringVar(&pullSecretNamespace, "shared-pull-secret-namespace", "",
+		"The namespace holding the shared image pull secrets Evas may copy into their namespace.")
+	flag.StringVar(&defaultCPURequest, "default-cpu-request", "100m", "The CPU request set on new Evas that set none.")
+	flag.StringVar(&defaultMemoryRequest, "default-memory-request", "128Mi", "The memory request set on new Evas that set none.")
+	flag.StringVar(&defaultCPULimit, "default-cpu-limit", "", "The CPU limit set on new Evas that set none.")
+	flag.StringVar(&defaultMemoryLimit, "default-memory-limit", "", "The memory limit set on new Evas that set none.")
+	flag.IntVar(&defaultBackoffLimit, "default-backoff-limit", 0, "The Job backoff limit set on new Evas that set none.")
+	flag.DurationVar(&archiveTTL, "archive-ttl", 7*24*time.Hour,
+		"How long the ConfigMap archiving a deleted Eva is kept. Zero keeps archives forever.")
+	flag.BoolVar(&migrateStorageVersion, "migrate-storage-version", false,
+		"If set, rewrite all stored Evas in the storage version on startup and drop older stored versions from the CRD.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -154,6 +189,12 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	secretNamespaces, err := secretCacheNamespaces(pullSecretNamespace)
+	if err != nil {
+		setupLog.Error(err, "unable to select the Secrets to cache")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -172,6 +213,15 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+
+		// Only pods of Eva workloads are watched, so keep the rest of the
+		// cluster's pods out of the cache
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:    {Label: labels.SelectorFromSet(labels.Set{"app": "eva-controller"})},
+				&corev1.Secret{}: {Namespaces: secretNamespaces},
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -179,14 +229,53 @@ func main() {
 	}
 
 	evaReconciler := &eva.EvaReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		APIReader:           mgr.GetAPIReader(),
+		PullSecretNamespace: pullSecretNamespace,
+		ArchiveTTL:          archiveTTL,
+		// The validating webhook checks the permissions Evas grant
+		PermissionsReviewed: os.Getenv("ENABLE_WEBHOOKS") != "false",
 	}
 
 	if err := evaReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Eva")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		evaDefaults := webhookv1alpha1.EvaDefaults{
+			ImagePullSecret: defaultImagePullSecret,
+			BackoffLimit:    int32(defaultBackoffLimit),
+		}
+		evaDefaults.Resources.Requests, err = parseResourceList(defaultCPURequest, defaultMemoryRequest)
+		if err != nil {
+			setupLog.Error(err, "invalid default resource requests")
+			os.Exit(1)
+		}
+		evaDefaults.Resources.Limits, err = parseResourceList(defaultCPULimit, defaultMemoryLimit)
+		if err != nil {
+			setupLog.Error(err, "invalid default resource limits")
+			os.Exit(1)
+		}
+		if err := webhookv1alpha1.SetupEvaWebhookWithManager(mgr, evaDefaults); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Eva")
+			os.Exit(1)
+		}
+		if err := webhookv1beta1.SetupEvaWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Eva")
+			os.Exit(1)
+		}
+	}
+	if migrateStorageVersion {
+		if err := mgr.Add(&migration.StorageVersionMigrator{
+			Client: mgr.GetClient(),
+			Reader: mgr.GetAPIReader(),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up storage version migration")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -204,3 +293,38 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseResourceList builds a ResourceList from CPU and memory quantities, skipping empty ones
+func parseResourceList(cpu, memory string) (corev1.ResourceList, error) {
+	resources := corev1.ResourceList{}
+	for name, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		resources[name] = quantity
+	}
+	return resources, nil
+}
+
+// secretCacheNamespaces keeps only the pull secret copies of Evas in the cache,
+// along with the Secrets of the shared pull secret namespace that may be copied.
+// That namespace replaces the selector of all namespaces, so it selects the
+// copies of the Evas living there by their own value of the shareable label.
+func secretCacheNamespaces(pullSecretNamespace string) (map[string]cache.Config, error) {
+	namespaces := map[string]cache.Config{
+		cache.AllNamespaces: {LabelSelector: labels.SelectorFromSet(labels.Set{"app": "eva-controller"})},
+	}
+	if pullSecretNamespace != "" {
+		shareable, err := labels.NewRequirement(eva.ShareablePullSecretLabel, selection.In,
+			[]string{"true", eva.PullSecretCopyLabelValue})
+		if err != nil {
+			return nil, err
+		}
+		namespaces[pullSecretNamespace] = cache.Config{LabelSelector: labels.NewSelector().Add(*shareable)}
+	}
+	return namespaces, nil
+}
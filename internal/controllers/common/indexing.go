@@ -18,19 +18,24 @@ func IndexFieldByOwner(
 		context.Background(),
 		obj,
 		ownerKey,
-		func(rawObj client.Object) []string {
-			owner := metav1.GetControllerOf(rawObj)
-			if owner == nil {
-				return nil
-			}
-			if owner.Kind != ownerKind {
-				return nil
-			}
-			return []string{string(owner.UID)}
-		},
+		OwnerIndexFunc(ownerKind),
 	)
 }
 
+// OwnerIndexFunc returns an indexer that extracts the UID of the controlling owner of the given kind
+func OwnerIndexFunc(ownerKind string) client.IndexerFunc {
+	return func(rawObj client.Object) []string {
+		owner := metav1.GetControllerOf(rawObj)
+		if owner == nil {
+			return nil
+		}
+		if owner.Kind != ownerKind {
+			return nil
+		}
+		return []string{string(owner.UID)}
+	}
+}
+
 func SetupOwnerIndexes(mgr ctrl.Manager, ownerKind string, indexes map[client.Object]string) error {
 	for obj, ownerKey := range indexes {
 		if err := IndexFieldByOwner(mgr, obj, ownerKey, ownerKind); err != nil {
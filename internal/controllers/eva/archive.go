@@ -0,0 +1,133 @@
+package eva
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// archiveLabel marks the ConfigMaps holding the final state of deleted Evas
+const archiveLabel = "geofront.nerv.com/archive"
+
+// archiveExpiresAnnotation records when an archive is collected
+const archiveExpiresAnnotation = "geofront.nerv.com/archive-expires-at"
+
+// archiveCollectionInterval is how often expired archives are looked for
+const archiveCollectionInterval = time.Hour
+
+// archiveName names the archive of one Eva, so that Evas later created under
+// the same name get archives of their own. The Eva's name is truncated where the
+// result would be too long for a ConfigMap; the UID keeps it unique.
+func archiveName(eva *v1alpha1.Eva) string {
+	suffix := fmt.Sprintf("-archive-%s", eva.UID)
+	name := eva.Name
+	if len(name)+len(suffix) > validation.DNS1123SubdomainMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)], "-.")
+	}
+	return name + suffix
+}
+
+// archiveStatus records the final spec and status of the Eva in a ConfigMap
+// that is not owned by the Eva and therefore outlives it, until the archive TTL
+// has passed. A ConfigMap of the same name that is not the Eva's archive is left alone.
+func (r *EvaReconciler) archiveStatus(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	spec, err := json.Marshal(eva.Spec)
+	if err != nil {
+		return err
+	}
+	status, err := json.Marshal(eva.Status)
+	if err != nil {
+		return err
+	}
+	archive := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      archiveName(eva),
+			Namespace: eva.Namespace,
+			Labels:    r.generateLabels(eva, map[string]string{archiveLabel: "true"}),
+		},
+		Data: map[string]string{
+			"spec.json":    string(spec),
+			"status.json":  string(status),
+			"deletionTime": eva.DeletionTimestamp.UTC().Format(time.RFC3339),
+		},
+	}
+	if r.ArchiveTTL > 0 {
+		archive.Annotations = map[string]string{
+			archiveExpiresAnnotation: eva.DeletionTimestamp.Add(r.ArchiveTTL).UTC().Format(time.RFC3339),
+		}
+	}
+
+	err = r.Create(ctx, archive)
+	if apierrors.IsAlreadyExists(err) {
+		existing := &corev1.ConfigMap{}
+		if err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: archive.Namespace, Name: archive.Name}, existing); err != nil {
+			return err
+		}
+		if !isArchiveOf(existing, eva) {
+			logger.Info("Not archiving Eva status over a foreign ConfigMap", "eva", eva.Name, "configMap", archive.Name)
+			return nil
+		}
+		archive.ResourceVersion = existing.ResourceVersion
+		err = r.Update(ctx, archive)
+	}
+	if err != nil {
+		logger.Error(err, "failed to archive status: ", "error", err)
+		return err
+	}
+	logger.Info("Archived Eva status", "eva", eva.Name, "configMap", archive.Name)
+	return nil
+}
+
+func isArchiveOf(configMap *corev1.ConfigMap, eva *v1alpha1.Eva) bool {
+	return configMap.Labels[archiveLabel] == "true" && configMap.Labels["eva-name"] == eva.Name
+}
+
+// collectArchives deletes expired archives until the manager stops
+func (r *EvaReconciler) collectArchives(ctx context.Context) error {
+	logger := logf.FromContext(ctx).WithName("archive-collector")
+	ticker := time.NewTicker(archiveCollectionInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.deleteExpiredArchives(ctx, time.Now(), logger); err != nil {
+			logger.Error(err, "failed to collect expired archives")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteExpiredArchives deletes the archives whose TTL has passed. Archives
+// are listed from the API server, keeping ConfigMaps out of the cache.
+func (r *EvaReconciler) deleteExpiredArchives(ctx context.Context, now time.Time, logger logr.Logger) error {
+	archives := &corev1.ConfigMapList{}
+	if err := r.uncachedReader().List(ctx, archives, client.MatchingLabels{archiveLabel: "true"}); err != nil {
+		return err
+	}
+	for i := range archives.Items {
+		archive := &archives.Items[i]
+		expires, err := time.Parse(time.RFC3339, archive.Annotations[archiveExpiresAnnotation])
+		if err != nil || now.Before(expires) {
+			continue
+		}
+		if err := r.Delete(ctx, archive); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted expired Eva archive", "namespace", archive.Namespace, "configMap", archive.Name)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package eva
+
+import (
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// podInitContainers returns the init containers of the Eva's pods: its sidecars,
+// restarting always so that they run alongside the main container, followed
+// by its init containers
+func podInitContainers(eva *v1alpha1.Eva) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(eva.Spec.Sidecars)+len(eva.Spec.InitContainers))
+	for _, sidecar := range eva.Spec.Sidecars {
+		sidecar = *sidecar.DeepCopy()
+		sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+		containers = append(containers, sidecar)
+	}
+	for _, container := range eva.Spec.InitContainers {
+		containers = append(containers, *container.DeepCopy())
+	}
+	return containers
+}
+
+// containerType tells whether a container of the pod is an init container, a
+// sidecar or the main container
+func containerType(pod *corev1.Pod, name string) v1alpha1.EvaContainerType {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name != name {
+			continue
+		}
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			return v1alpha1.EvaContainerTypeSidecar
+		}
+		return v1alpha1.EvaContainerTypeInit
+	}
+	return v1alpha1.EvaContainerTypeMain
+}
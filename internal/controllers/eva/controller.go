@@ -18,34 +18,78 @@ package eva
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	kbatch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
 	"github.com/dayaliuzzo/Smooth-Operator/internal/controllers/common"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/registry"
 )
 
 const ownerKey = ".metadata.controller"
+
+// cronJobOwnerKey indexes the Jobs started by a CronJob by the CronJob's UID
+const cronJobOwnerKey = ".metadata.cronJobController"
 const evaFinalizer = "geofront.nerv.com/finalizer"
+const specHashAnnotation = "geofront.nerv.com/spec-hash"
+const evaGenerationAnnotation = "geofront.nerv.com/eva-generation"
+const attemptAnnotation = "geofront.nerv.com/attempt"
 
 type EvaReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads the Secrets of Eva namespaces and the archives of
+	// deleted Evas, which are not cached
+	APIReader client.Reader
+	// PullSecretNamespace holds the shared pull secrets Evas copy into their
+	// namespace
+	PullSecretNamespace string
+	// PermissionsReviewed is set when the validating webhook checks that the
+	// author of an Eva holds the permissions it grants. Without it Evas are
+	// granted none.
+	PermissionsReviewed bool
+	// ArchiveTTL is how long the archive of a deleted Eva is kept, forever when zero
+	ArchiveTTL time.Duration
+	// ImageResolver pins the images of Evas asking for it to a digest,
+	// querying the registries directly when nil
+	ImageResolver *registry.Resolver
 }
 
 // +kubebuilder:rbac:groups=geofront.nerv.com,resources=evas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=geofront.nerv.com,resources=evas/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=geofront.nerv.com,resources=evas/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -74,24 +118,31 @@ func (r *EvaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	statusUpdate, err := r.reconcileResources(ctx, &eva, &currentState, logger)
+	statusUpdate, result, err := r.reconcileResources(ctx, &eva, &currentState, logger)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	if err := r.updateStatusIfChanged(ctx, &eva, statusUpdate); err != nil {
 		return ctrl.Result{}, err
 	}
-	logger.Info("Reconciliation complete", "statusUpdate", statusUpdate)
+	logger.Info("Reconciliation complete", "statusUpdate", statusUpdate, "requeueAfter", result.RequeueAfter)
 
 	// TODO(user): your logic here
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
+// addFinalizer adds the finalizer to an Eva the controller sees for the first
+// time, opting it in to the restricted defaults unless its author chose
+// otherwise. Evas that ran before the defaults already hold the finalizer, so
+// they keep running without them.
 func (r *EvaReconciler) addFinalizer(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) (ctrl.Result, error) {
 	if !controllerutil.ContainsFinalizer(eva, evaFinalizer) {
 		logger.Info("Adding finalizer", "finalizer", evaFinalizer)
 		controllerutil.AddFinalizer(eva, evaFinalizer)
+		if _, ok := eva.Annotations[v1alpha1.EvaRestrictedDefaultsAnnotation]; !ok {
+			metav1.SetMetaDataAnnotation(&eva.ObjectMeta, v1alpha1.EvaRestrictedDefaultsAnnotation, "true")
+		}
 
 		if err := r.Update(ctx, eva); err != nil {
 			return ctrl.Result{}, err
@@ -117,18 +168,51 @@ func (r *EvaReconciler) updateStatusIfChanged(ctx context.Context, eva *v1alpha1
 
 	phaseChanged := eva.Status.Phase != statusUpdate.Phase
 	generationChanged := eva.Status.ObservedGeneration != eva.Generation
-	if !phaseChanged && !generationChanged && !conditionsChanged {
+	specHashChanged := statusUpdate.SpecHash != "" &&
+		(eva.Status.SpecHash != statusUpdate.SpecHash || eva.Status.JobGeneration != statusUpdate.JobGeneration ||
+			eva.Status.ResolvedImage != statusUpdate.ResolvedImage)
+	runChanged := statusUpdate.JobRef != nil && runStatusChanged(&eva.Status, statusUpdate)
+	attemptChanged := statusUpdate.CurrentAttempt != 0 &&
+		(eva.Status.CurrentAttempt != statusUpdate.CurrentAttempt ||
+			!equality.Semantic.DeepEqual(eva.Status.AttemptHistory, statusUpdate.AttemptHistory))
+	scheduleChanged := statusUpdate.RecentRuns != nil && scheduleStatusChanged(&eva.Status, statusUpdate)
+	if !phaseChanged && !generationChanged && !conditionsChanged && !specHashChanged && !runChanged && !attemptChanged && !scheduleChanged {
 		logger.V(1).Info("Status unchanged, skipping update")
 		return nil
 	}
 
-	logger.Info("Status update needed", "phaseChanged", phaseChanged, "generationChanged", generationChanged, "conditionsChanged", conditionsChanged)
+	logger.Info("Status update needed", "phaseChanged", phaseChanged, "generationChanged", generationChanged, "conditionsChanged", conditionsChanged, "specHashChanged", specHashChanged, "runChanged", runChanged, "attemptChanged", attemptChanged, "scheduleChanged", scheduleChanged)
 
 	for _, condition := range statusUpdate.Conditions {
 		meta.SetStatusCondition(&eva.Status.Conditions, condition)
 	}
 	eva.Status.Phase = statusUpdate.Phase
 	eva.Status.ObservedGeneration = eva.Generation
+	if statusUpdate.SpecHash != "" {
+		eva.Status.SpecHash = statusUpdate.SpecHash
+		eva.Status.JobGeneration = statusUpdate.JobGeneration
+		eva.Status.ResolvedImage = statusUpdate.ResolvedImage
+	}
+	if statusUpdate.JobRef != nil {
+		eva.Status.JobRef = statusUpdate.JobRef
+		eva.Status.StartTime = statusUpdate.StartTime
+		eva.Status.CompletionTime = statusUpdate.CompletionTime
+		eva.Status.PodRefs = statusUpdate.PodRefs
+		eva.Status.Attempts = statusUpdate.Attempts
+		eva.Status.Containers = statusUpdate.Containers
+		eva.Status.Progress = statusUpdate.Progress
+		eva.Status.CompletedIndexes = statusUpdate.CompletedIndexes
+		eva.Status.FailedIndexes = statusUpdate.FailedIndexes
+	}
+	if statusUpdate.CurrentAttempt != 0 {
+		eva.Status.CurrentAttempt = statusUpdate.CurrentAttempt
+		eva.Status.AttemptHistory = statusUpdate.AttemptHistory
+	}
+	if statusUpdate.RecentRuns != nil {
+		eva.Status.LastScheduleTime = statusUpdate.LastScheduleTime
+		eva.Status.LastSuccessfulTime = statusUpdate.LastSuccessfulTime
+		eva.Status.RecentRuns = statusUpdate.RecentRuns
+	}
 
 	err := r.Status().Update(ctx, eva)
 	if err != nil && apierrors.IsConflict(err) {
@@ -138,31 +222,165 @@ func (r *EvaReconciler) updateStatusIfChanged(ctx context.Context, eva *v1alpha1
 	return err
 }
 
+// runStatusChanged reports whether the observed run details differ from the recorded ones
+func runStatusChanged(current, observed *v1alpha1.EvaStatus) bool {
+	return !equality.Semantic.DeepEqual(current.JobRef, observed.JobRef) ||
+		!equality.Semantic.DeepEqual(current.StartTime, observed.StartTime) ||
+		!equality.Semantic.DeepEqual(current.CompletionTime, observed.CompletionTime) ||
+		!equality.Semantic.DeepEqual(current.PodRefs, observed.PodRefs) ||
+		current.Attempts != observed.Attempts ||
+		!equality.Semantic.DeepEqual(current.Containers, observed.Containers) ||
+		current.Progress != observed.Progress ||
+		current.CompletedIndexes != observed.CompletedIndexes ||
+		current.FailedIndexes != observed.FailedIndexes
+}
+
+// scheduleStatusChanged reports whether the observed schedule details differ from the recorded ones
+func scheduleStatusChanged(current, observed *v1alpha1.EvaStatus) bool {
+	return !equality.Semantic.DeepEqual(current.LastScheduleTime, observed.LastScheduleTime) ||
+		!equality.Semantic.DeepEqual(current.LastSuccessfulTime, observed.LastSuccessfulTime) ||
+		!equality.Semantic.DeepEqual(current.RecentRuns, observed.RecentRuns)
+}
+
 func (r *EvaReconciler) handleDelete(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) (ctrl.Result, error) {
-	logger.Info("Removing finalizer", "finalizer", evaFinalizer)
-	if controllerutil.ContainsFinalizer(eva, evaFinalizer) {
-		controllerutil.RemoveFinalizer(eva, evaFinalizer)
-		if err := r.Update(ctx, eva); err != nil {
+	if !controllerutil.ContainsFinalizer(eva, evaFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := eva.Spec.DeletionPolicy
+	if policy != v1alpha1.EvaDeletionPolicyOrphan {
+		running, err := r.stopWorkload(ctx, eva, logger)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if running > 0 && !deletionTimedOut(eva) {
+			logger.Info("Waiting for pods to terminate", "running", running, "deletionPolicy", policy)
+			statusUpdate := &v1alpha1.EvaStatus{
+				Phase: v1alpha1.EvaPhaseTerminating,
+				Conditions: evaConditions(eva,
+					metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse,
+					"Terminating", fmt.Sprintf("Waiting for %d pod(s) to terminate.", running)),
+			}
+			if err := r.updateStatusIfChanged(ctx, eva, statusUpdate); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: terminationPollInterval}, nil
+		}
+		if running > 0 {
+			logger.Info("Deletion timeout exceeded, removing finalizer with pods still running", "running", running)
+		}
+	}
+
+	if err := r.archiveStatus(ctx, eva, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.releaseStorage(ctx, eva, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.releasePermissions(ctx, eva, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	if policy == v1alpha1.EvaDeletionPolicyOrphan || policy == v1alpha1.EvaDeletionPolicyRetain {
+		if err := r.orphanWorkload(ctx, eva, logger); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
+
+	logger.Info("Removing finalizer", "finalizer", evaFinalizer, "deletionPolicy", policy)
+	controllerutil.RemoveFinalizer(eva, evaFinalizer)
+	if err := r.Update(ctx, eva); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
 func (r *EvaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := common.SetupOwnerIndexes(mgr, "Eva", map[client.Object]string{
-		&kbatch.Job{}:        ownerKey,
-		&corev1.Service{}:    ownerKey,
-		&appsv1.Deployment{}: ownerKey,
+		&kbatch.Job{}:                   ownerKey,
+		&corev1.Service{}:               ownerKey,
+		&appsv1.Deployment{}:            ownerKey,
+		&kbatch.CronJob{}:               ownerKey,
+		&corev1.PersistentVolumeClaim{}: ownerKey,
+		&corev1.ServiceAccount{}:        ownerKey,
+		&rbacv1.Role{}:                  ownerKey,
+		&rbacv1.RoleBinding{}:           ownerKey,
+		&corev1.Secret{}:                ownerKey,
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Eva{}, sharedPullSecretKey, indexSharedPullSecrets); err != nil {
+		return err
+	}
+	if err := common.SetupOwnerIndexes(mgr, "CronJob", map[client.Object]string{
+		&kbatch.Job{}: cronJobOwnerKey,
 	}); err != nil {
 		return err
 	}
 
+	if err := mgr.Add(manager.RunnableFunc(r.collectArchives)); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Eva{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&kbatch.Job{}).
+		Owns(&kbatch.CronJob{}).
 		Owns(&corev1.Service{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&corev1.Secret{}).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSharedPullSecretToEvas)).
+		Watches(&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.mapPodToEva),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isEvaPod))).
+		Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToEvas),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Named("eva").
 		Complete(r)
 }
+
+// isEvaPod reports whether the pod was created for an Eva workload
+func isEvaPod(obj client.Object) bool {
+	return obj.GetLabels()["app"] == "eva-controller"
+}
+
+// mapPodToEva enqueues the Eva a pod belongs to, found through its eva-name
+// label or, for pods whose labels were edited, through the owning Job
+func (r *EvaReconciler) mapPodToEva(ctx context.Context, obj client.Object) []reconcile.Request {
+	if name := obj.GetLabels()["eva-name"]; name != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "Job" {
+		return nil
+	}
+	job := &kbatch.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: owner.Name}, job); err != nil {
+		return nil
+	}
+	jobOwner := metav1.GetControllerOf(job)
+	if jobOwner == nil || jobOwner.Kind != "Eva" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: jobOwner.Name}}}
+}
+
+// mapNamespaceToEvas enqueues every Eva in a namespace whose labels, and with
+// them the enforced Pod Security level, changed
+func (r *EvaReconciler) mapNamespaceToEvas(ctx context.Context, obj client.Object) []reconcile.Request {
+	evaList := &v1alpha1.EvaList{}
+	if err := r.List(ctx, evaList, client.InNamespace(obj.GetName())); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(evaList.Items))
+	for _, eva := range evaList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: eva.Namespace, Name: eva.Name}})
+	}
+	return requests
+}
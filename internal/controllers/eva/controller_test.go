@@ -18,20 +18,40 @@ package eva
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	kbatch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/controllers/common"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/registry"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/registry/registrytest"
 )
 
 var _ = Describe("Eva Controller", func() {
-	Context("When reconciling a resource", func() {
+	Context("When reconciling a resource", Label("envtest"), func() {
 		const resourceName = "test-resource"
 
 		ctx := context.Background()
@@ -81,4 +101,1880 @@ var _ = Describe("Eva Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When reconciling a Deployment-mode Eva", Label("envtest"), func() {
+		const resourceName = "test-deployment"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a Deployment-mode Eva")
+			resource := &geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "nginx:latest",
+					Mode:  geofrontv1alpha1.EvaModeDeployment,
+					Port:  80,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &geofrontv1alpha1.Eva{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should create a Deployment and a fronting Service", func() {
+			controllerReconciler := &EvaReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			By("Reconciling twice to add the finalizer and then the resources")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-deployment", Namespace: "default"}, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-service", Namespace: "default"}, service)).To(Succeed())
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(80)))
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+		})
+	})
+
+	Context("When the spec of a Deployment-mode Eva changes", func() {
+		const resourceName = "test-deployment-update"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		deploymentName := types.NamespacedName{Name: resourceName + "-deployment", Namespace: "default"}
+		serviceName := types.NamespacedName{Name: resourceName + "-service", Namespace: "default"}
+
+		It("should drop removed fields from the Deployment and the Service with the port", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "nginx:latest",
+					Mode:  geofrontv1alpha1.EvaModeDeployment,
+					Port:  80,
+					Env: []corev1.EnvVar{
+						{Name: "GREETING", Value: "hello"},
+						{Name: "TARGET", Value: "world"},
+					},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			deployment := &appsv1.Deployment{}
+			Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(2))
+			Expect(controllerReconciler.Get(ctx, serviceName, &corev1.Service{})).To(Succeed())
+
+			By("Dropping an env var and the port")
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			eva.Spec.Env = eva.Spec.Env[:1]
+			eva.Spec.Port = 0
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.Env).To(Equal([]corev1.EnvVar{{Name: "GREETING", Value: "hello"}}))
+			Expect(container.Ports).To(BeEmpty())
+			err := controllerReconciler.Get(ctx, serviceName, &corev1.Service{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should leave a Deployment alone when only API server defaults differ", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "nginx:latest",
+					Mode:  geofrontv1alpha1.EvaModeDeployment,
+					Port:  80,
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			By("Defaulting the template as the API server does")
+			deployment := &appsv1.Deployment{}
+			Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+			container := &deployment.Spec.Template.Spec.Containers[0]
+			container.TerminationMessagePath = corev1.TerminationMessagePathDefault
+			container.ImagePullPolicy = corev1.PullAlways
+			deployment.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+			deployment.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+			Expect(controllerReconciler.Update(ctx, deployment)).To(Succeed())
+			resourceVersion := deployment.ResourceVersion
+
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+			Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+
+	Context("When reconciling a CronJob-mode Eva", func() {
+		const resourceName = "test-cronjob"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		cronJobName := types.NamespacedName{Name: resourceName + "-cronjob", Namespace: "default"}
+
+		It("should own a CronJob and surface its runs", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "busybox:1.36",
+					Mode:  geofrontv1alpha1.EvaModeCronJob,
+					Schedule: &geofrontv1alpha1.EvaSchedule{
+						Cron:     "0 3 * * *",
+						TimeZone: ptr.To("Asia/Tokyo"),
+					},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			cronJob := &kbatch.CronJob{}
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			Expect(cronJob.Spec.Schedule).To(Equal("0 3 * * *"))
+			Expect(cronJob.Spec.TimeZone).To(HaveValue(Equal("Asia/Tokyo")))
+			Expect(cronJob.Spec.ConcurrencyPolicy).To(Equal(kbatch.ForbidConcurrent))
+			Expect(cronJob.Spec.SuccessfulJobsHistoryLimit).To(HaveValue(Equal(int32(3))))
+			Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image).To(Equal("busybox:1.36"))
+			Expect(cronJob.Spec.JobTemplate.Spec.Suspend).To(BeNil())
+
+			By("Finishing a scheduled run with a failure")
+			scheduled := metav1.Now()
+			cronJob.Status.LastScheduleTime = &scheduled
+			Expect(controllerReconciler.Status().Update(ctx, cronJob)).To(Succeed())
+			Expect(controllerReconciler.Create(ctx, &kbatch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-cronjob-29000000",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "batch/v1",
+						Kind:       "CronJob",
+						Name:       cronJob.Name,
+						UID:        cronJob.UID,
+						Controller: ptr.To(true),
+					}},
+				},
+				Spec: cronJob.Spec.JobTemplate.Spec,
+				Status: kbatch.JobStatus{
+					StartTime: &scheduled,
+					Conditions: []kbatch.JobCondition{{
+						Type:               kbatch.JobFailed,
+						Status:             corev1.ConditionTrue,
+						Reason:             "BackoffLimitExceeded",
+						LastTransitionTime: scheduled,
+					}},
+				},
+			})).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseRunning))
+			Expect(eva.Status.LastScheduleTime).NotTo(BeNil())
+			Expect(eva.Status.RecentRuns).To(HaveLen(1))
+			Expect(eva.Status.RecentRuns[0].Phase).To(Equal(geofrontv1alpha1.EvaPhaseFailed))
+			degraded := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionDegraded))
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Reason).To(Equal("LastRunFailed"))
+
+			By("Pausing the Eva")
+			eva.Spec.Paused = true
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			Expect(cronJob.Spec.Suspend).To(HaveValue(BeTrue()))
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePaused))
+		})
+	})
+
+	Context("When the spec of a CronJob-mode Eva changes", func() {
+		const resourceName = "test-cronjob-update"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		cronJobName := types.NamespacedName{Name: resourceName + "-cronjob", Namespace: "default"}
+
+		It("should drop removed fields from the CronJob", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Mode = geofrontv1alpha1.EvaModeCronJob
+				spec.Schedule = &geofrontv1alpha1.EvaSchedule{Cron: "@daily", StartingDeadlineSeconds: ptr.To(int64(300))}
+				spec.Env = []corev1.EnvVar{{Name: "GREETING", Value: "hello"}}
+				spec.Scheduling.NodeSelector = map[string]string{"nerv.com/pool": "cage-07"}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			cronJob := &kbatch.CronJob{}
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			Expect(cronJob.Spec.StartingDeadlineSeconds).To(HaveValue(Equal(int64(300))))
+			Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+
+			By("Removing the env var, node selector and starting deadline")
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			eva.Spec.Env = nil
+			eva.Spec.Scheduling.NodeSelector = nil
+			eva.Spec.Schedule.StartingDeadlineSeconds = nil
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			Expect(cronJob.Spec.StartingDeadlineSeconds).To(BeNil())
+			Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+		})
+
+		It("should leave a CronJob alone when only API server defaults differ", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Mode = geofrontv1alpha1.EvaModeCronJob
+				spec.Schedule = &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			By("Defaulting the template as the API server does")
+			cronJob := &kbatch.CronJob{}
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			podSpec := &cronJob.Spec.JobTemplate.Spec.Template.Spec
+			podSpec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+			podSpec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+			podSpec.DNSPolicy = corev1.DNSClusterFirst
+			podSpec.SchedulerName = corev1.DefaultSchedulerName
+			Expect(controllerReconciler.Update(ctx, cronJob)).To(Succeed())
+			resourceVersion := cronJob.ResourceVersion
+
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, cronJobName, cronJob)).To(Succeed())
+			Expect(cronJob.ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+
+	Context("When the spec of a Job-mode Eva changes", func() {
+		const resourceName = "test-update-strategy"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		It("should replace the Job with one built from the new spec", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					UID:        types.UID(resourceName),
+					Generation: 1,
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image:          "busybox:1.36",
+					UpdateStrategy: geofrontv1alpha1.EvaUpdateStrategyReplace,
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			originalHash := job.Annotations[specHashAnnotation]
+			Expect(originalHash).NotTo(BeEmpty())
+
+			By("Editing the image")
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			eva.Spec.Image = "busybox:1.37"
+			eva.Generation = 2
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+
+			By("Reconciling once to delete the outdated Job and once to recreate it")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("busybox:1.37"))
+			Expect(job.Annotations[specHashAnnotation]).NotTo(Equal(originalHash))
+
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.SpecHash).To(Equal(job.Annotations[specHashAnnotation]))
+			Expect(eva.Status.JobGeneration).To(Equal(int64(2)))
+		})
+	})
+
+	Context("When a Job-mode Eva is paused", func() {
+		const resourceName = "test-paused"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		It("should suspend the Job and resume it when unpaused", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image:  "busybox:1.36",
+					Paused: true,
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Suspend).To(HaveValue(BeTrue()))
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePaused))
+			Expect(meta.IsStatusConditionTrue(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPaused))).To(BeTrue())
+
+			By("Resuming the Eva")
+			eva.Spec.Paused = false
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Suspend).To(HaveValue(BeFalse()))
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseRunning))
+			Expect(meta.IsStatusConditionFalse(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPaused))).To(BeTrue())
+		})
+	})
+
+	Context("When a Job-mode Eva runs to an outcome", func() {
+		const resourceName = "test-conditions"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		It("should keep every condition in step with the phase", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{Image: "busybox:1.36"},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+			Expect(meta.IsStatusConditionTrue(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionProgressing))).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionReady))).To(BeTrue())
+
+			By("Reconciling the running Job")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseRunning))
+			ready := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionReady))
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+			readySince := ready.LastTransitionTime
+
+			By("Failing the Job")
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			job.Status.Conditions = append(job.Status.Conditions, kbatch.JobCondition{
+				Type:   kbatch.JobFailed,
+				Status: corev1.ConditionTrue,
+			})
+			Expect(controllerReconciler.Status().Update(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseFailed))
+			for _, conditionType := range []geofrontv1alpha1.EvaConditionType{
+				geofrontv1alpha1.EvaConditionFailed, geofrontv1alpha1.EvaConditionDegraded,
+			} {
+				Expect(meta.IsStatusConditionTrue(eva.Status.Conditions, string(conditionType))).To(BeTrue())
+			}
+			for _, conditionType := range []geofrontv1alpha1.EvaConditionType{
+				geofrontv1alpha1.EvaConditionAvailable, geofrontv1alpha1.EvaConditionProgressing, geofrontv1alpha1.EvaConditionReady,
+			} {
+				Expect(meta.IsStatusConditionFalse(eva.Status.Conditions, string(conditionType))).To(BeTrue())
+			}
+			ready = meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionReady))
+			Expect(ready.Reason).To(Equal("JobFailed"))
+			Expect(ready.LastTransitionTime.Before(&readySince)).To(BeFalse())
+		})
+	})
+
+	Context("When the pod of a Job-mode Eva is terminated", func() {
+		const resourceName = "test-run-status"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		It("should record the run timing, children and container outcome", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{Image: "busybox:1.36"},
+			}, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-job-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": resourceName + "-job"},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodFailed,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: resourceName + "-container",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 137,
+							Reason:   "OOMKilled",
+							Message:  "sync ratio exceeded",
+						}},
+					}},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			By("Failing the Job")
+			started := metav1.NewTime(metav1.Now().Add(-time.Minute).Truncate(time.Second))
+			failed := metav1.NewTime(metav1.Now().Truncate(time.Second))
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			job.Status.StartTime = &started
+			job.Status.Failed = 1
+			job.Status.Conditions = append(job.Status.Conditions, kbatch.JobCondition{
+				Type:               kbatch.JobFailed,
+				Status:             corev1.ConditionTrue,
+				Reason:             "BackoffLimitExceeded",
+				LastTransitionTime: failed,
+			})
+			Expect(controllerReconciler.Status().Update(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseFailed))
+			Expect(eva.Status.JobRef).NotTo(BeNil())
+			Expect(eva.Status.JobRef.Name).To(Equal(jobName.Name))
+			Expect(eva.Status.StartTime.Equal(&started)).To(BeTrue())
+			Expect(eva.Status.CompletionTime.Equal(&failed)).To(BeTrue())
+			Expect(eva.Status.Attempts).To(Equal(int32(1)))
+			Expect(eva.Status.PodRefs).To(HaveLen(1))
+			Expect(eva.Status.PodRefs[0].Name).To(Equal(resourceName + "-job-abcde"))
+			Expect(eva.Status.Containers).To(HaveLen(1))
+			Expect(eva.Status.Containers[0].ExitCode).To(HaveValue(Equal(int32(137))))
+			Expect(eva.Status.Containers[0].Reason).To(Equal("OOMKilled"))
+			Expect(eva.Status.Containers[0].Message).To(Equal("sync ratio exceeded"))
+		})
+	})
+
+	Context("When the pod of a Job-mode Eva cannot be scheduled", func() {
+		const resourceName = "test-unschedulable"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should stay Pending with a Degraded condition instead of Running", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{Image: "busybox:1.36"},
+			}, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-job-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": resourceName + "-job"},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					Conditions: []corev1.PodCondition{{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/3 nodes are available: 3 Insufficient cpu.",
+					}},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 3)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+			degraded := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionDegraded))
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("Unschedulable"))
+			Expect(degraded.Message).To(ContainSubstring("Insufficient cpu"))
+			Expect(meta.IsStatusConditionFalse(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionFailed))).To(BeTrue())
+		})
+	})
+
+	Context("When the image of a Job-mode Eva cannot be pulled", func() {
+		const resourceName = "test-image-pull"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		newObjects := func(podStarted time.Time) []client.Object {
+			started := metav1.NewTime(podStarted)
+			return []client.Object{&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "registry.nerv.com/eva:missing",
+					FailurePolicy: &geofrontv1alpha1.EvaFailurePolicy{
+						ImagePullGracePeriod: &metav1.Duration{Duration: 5 * time.Minute},
+					},
+				},
+			}, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-job-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": resourceName + "-job"},
+				},
+				Status: corev1.PodStatus{
+					Phase:     corev1.PodPending,
+					StartTime: &started,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: resourceName + "-container",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+							Reason:  "ErrImagePull",
+							Message: "manifest unknown",
+						}},
+					}},
+				},
+			}}
+		}
+
+		It("should stay Pending and requeue within the grace period", func() {
+			controllerReconciler := newFakeReconciler(newObjects(time.Now())...)
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 4*time.Minute))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+			degraded := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionDegraded))
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("ImagePullBackOff"))
+			Expect(degraded.Message).To(ContainSubstring("manifest unknown"))
+		})
+
+		It("should fail the Eva once the grace period has expired", func() {
+			controllerReconciler := newFakeReconciler(newObjects(time.Now().Add(-10 * time.Minute))...)
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 3)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseFailed))
+			Expect(meta.IsStatusConditionTrue(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionFailed))).To(BeTrue())
+		})
+	})
+
+	Context("When the Job of an Eva with a retry policy fails", func() {
+		const resourceName = "test-retry"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		failJob := func(r *EvaReconciler, name string) {
+			job := &kbatch.Job{}
+			Expect(r.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, job)).To(Succeed())
+			job.Status.Conditions = append(job.Status.Conditions, kbatch.JobCondition{
+				Type:               kbatch.JobFailed,
+				Status:             corev1.ConditionTrue,
+				Reason:             "BackoffLimitExceeded",
+				LastTransitionTime: metav1.Now(),
+			})
+			Expect(r.Status().Update(ctx, job)).To(Succeed())
+		}
+
+		It("should run a fresh Job per attempt until the attempts run out", func() {
+			controllerReconciler := newFakeReconciler(&geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					UID:       types.UID(resourceName),
+				},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "busybox:1.36",
+					RetryPolicy: &geofrontv1alpha1.EvaRetryPolicy{
+						MaxAttempts: 2,
+						Backoff:     &metav1.Duration{},
+					},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			By("Failing the first attempt")
+			failJob(controllerReconciler, resourceName+"-job")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+			Expect(eva.Status.CurrentAttempt).To(Equal(int32(2)))
+			Expect(eva.Status.AttemptHistory).To(HaveLen(1))
+			Expect(eva.Status.AttemptHistory[0].Reason).To(Equal("BackoffLimitExceeded"))
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx,
+				types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}, &kbatch.Job{}))).To(BeTrue())
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job-2", Namespace: "default"}, job)).To(Succeed())
+			Expect(job.Annotations).To(HaveKeyWithValue(attemptAnnotation, "2"))
+
+			By("Failing the last attempt")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			failJob(controllerReconciler, resourceName+"-job-2")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseFailed))
+			Expect(eva.Status.AttemptHistory).To(HaveLen(2))
+			Expect(eva.Status.AttemptHistory[1].JobName).To(Equal(resourceName + "-job-2"))
+		})
+	})
+
+	Context("When an Eva sets its container spec", func() {
+		It("should apply it identically to the Job and the Deployment", func() {
+			eva := &geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-container-spec", Namespace: "default"},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image:      "busybox:1.36",
+					Command:    []string{"/bin/sync"},
+					Args:       []string{"--ratio=400"},
+					WorkingDir: "/srv",
+					Env: []corev1.EnvVar{
+						{Name: "PILOT", Value: "Shinji"},
+						{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "nerv-token"},
+							Key:                  "token",
+						}}},
+					},
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "magi"},
+					}}},
+					Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+					},
+				},
+			}
+			controllerReconciler := newFakeReconciler()
+			job, err := controllerReconciler.desiredJob(eva, 1)
+			Expect(err).NotTo(HaveOccurred())
+			deployment := controllerReconciler.desiredDeployment(eva)
+
+			jobContainer := job.Spec.Template.Spec.Containers[0]
+			Expect(jobContainer.Args).To(Equal([]string{"--ratio=400"}))
+			Expect(jobContainer.WorkingDir).To(Equal("/srv"))
+			Expect(jobContainer.Env).To(HaveLen(2))
+			Expect(jobContainer.EnvFrom).To(HaveLen(1))
+			Expect(jobContainer.Ports).To(HaveLen(1))
+			Expect(jobContainer.Resources.Limits.Memory().String()).To(Equal("256Mi"))
+			Expect(deployment.Spec.Template.Spec.Containers[0]).To(Equal(jobContainer))
+		})
+
+		It("should add the exposed port to the Deployment unless the container declares it", func() {
+			eva := &geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-container-ports", Namespace: "default"},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image: "nginx:latest",
+					Mode:  geofrontv1alpha1.EvaModeDeployment,
+					Port:  80,
+					Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}},
+				},
+			}
+			controllerReconciler := newFakeReconciler()
+			Expect(controllerReconciler.desiredDeployment(eva).Spec.Template.Spec.Containers[0].Ports).To(HaveLen(2))
+
+			eva.Spec.Ports = append(eva.Spec.Ports, corev1.ContainerPort{Name: "http", ContainerPort: 80})
+			Expect(controllerReconciler.desiredDeployment(eva).Spec.Template.Spec.Containers[0].Ports).To(HaveLen(2))
+		})
+	})
+
+	Context("When an Eva sets Job limits", func() {
+		It("should carry them on the Job without counting the TTL as a spec change", func() {
+			eva := &geofrontv1alpha1.Eva{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job-limits", Namespace: "default"},
+				Spec: geofrontv1alpha1.EvaSpec{
+					Image:                 "busybox:1.36",
+					ActiveDeadlineSeconds: ptr.To(int64(600)),
+					PodFailurePolicy: &kbatch.PodFailurePolicy{Rules: []kbatch.PodFailurePolicyRule{{
+						Action: kbatch.PodFailurePolicyActionFailJob,
+						OnExitCodes: &kbatch.PodFailurePolicyOnExitCodesRequirement{
+							Operator: kbatch.PodFailurePolicyOnExitCodesOpIn,
+							Values:   []int32{42},
+						},
+					}}},
+				},
+			}
+			controllerReconciler := newFakeReconciler()
+			job, err := controllerReconciler.desiredJob(eva, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Spec.ActiveDeadlineSeconds).To(HaveValue(Equal(int64(600))))
+			Expect(job.Spec.PodFailurePolicy.Rules).To(HaveLen(1))
+			Expect(job.Spec.TTLSecondsAfterFinished).To(BeNil())
+
+			eva.Spec.TTLSecondsAfterFinished = ptr.To(int32(300))
+			withTTL, err := controllerReconciler.desiredJob(eva, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(withTTL.Spec.TTLSecondsAfterFinished).To(HaveValue(Equal(int32(300))))
+			Expect(withTTL.Annotations[specHashAnnotation]).To(Equal(job.Annotations[specHashAnnotation]))
+		})
+	})
+
+	Context("When a pod of an Eva changes", func() {
+		const resourceName = "test-pod-watch"
+
+		ctx := context.Background()
+
+		evaRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}}
+
+		It("should map the pod to its Eva through the eva-name label", func() {
+			controllerReconciler := newFakeReconciler()
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      resourceName + "-pod",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "eva-controller", "eva-name": resourceName},
+			}}
+			Expect(isEvaPod(pod)).To(BeTrue())
+			Expect(controllerReconciler.mapPodToEva(ctx, pod)).To(ConsistOf(evaRequest))
+		})
+
+		It("should map the pod to its Eva through the owning Job", func() {
+			controllerReconciler := newFakeReconciler(&kbatch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-job",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: geofrontv1alpha1.GroupVersion.String(),
+						Kind:       "Eva",
+						Name:       resourceName,
+						UID:        types.UID(resourceName),
+						Controller: ptr.To(true),
+					}},
+				},
+			})
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      resourceName + "-pod",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "eva-controller"},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: kbatch.SchemeGroupVersion.String(),
+					Kind:       "Job",
+					Name:       resourceName + "-job",
+					UID:        types.UID(resourceName + "-job"),
+					Controller: ptr.To(true),
+				}},
+			}}
+			Expect(controllerReconciler.mapPodToEva(ctx, pod)).To(ConsistOf(evaRequest))
+		})
+
+		It("should ignore pods that do not belong to an Eva", func() {
+			controllerReconciler := newFakeReconciler()
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}}
+			Expect(isEvaPod(pod)).To(BeFalse())
+			Expect(controllerReconciler.mapPodToEva(ctx, pod)).To(BeEmpty())
+		})
+	})
+
+	Context("When an Eva has init and sidecar containers", func() {
+		const resourceName = "test-sidecars"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		withContainers := func(spec *geofrontv1alpha1.EvaSpec) {
+			spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "busybox:1.36"}}
+			spec.Sidecars = []corev1.Container{{Name: "log-shipper", Image: "fluent/fluent-bit:3.0"}}
+		}
+
+		It("should run the sidecars as native sidecars ahead of the init containers", func() {
+			eva := testEva(resourceName, withContainers)
+			metav1.SetMetaDataAnnotation(&eva.ObjectMeta, geofrontv1alpha1.EvaRestrictedDefaultsAnnotation, "true")
+			controllerReconciler := newFakeReconciler()
+			job, err := controllerReconciler.desiredJob(eva, 1)
+			Expect(err).NotTo(HaveOccurred())
+			deployment := controllerReconciler.desiredDeployment(eva)
+
+			for _, podSpec := range []corev1.PodSpec{job.Spec.Template.Spec, deployment.Spec.Template.Spec} {
+				Expect(podSpec.Containers).To(HaveLen(1))
+				Expect(podSpec.InitContainers).To(HaveLen(2))
+				Expect(podSpec.InitContainers[0].Name).To(Equal("log-shipper"))
+				Expect(podSpec.InitContainers[0].RestartPolicy).To(HaveValue(Equal(corev1.ContainerRestartPolicyAlways)))
+				Expect(podSpec.InitContainers[1].Name).To(Equal("migrate"))
+				Expect(podSpec.InitContainers[1].RestartPolicy).To(BeNil())
+				for _, container := range podSpec.InitContainers {
+					Expect(container.SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+					Expect(container.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")))
+				}
+			}
+		})
+
+		It("should report the state of every container of the pod", func() {
+			eva := testEva(resourceName, withContainers)
+			sidecar := eva.Spec.Sidecars[0]
+			sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+			controllerReconciler := newFakeReconciler(eva, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-job-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": resourceName + "-job"},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{sidecar, eva.Spec.InitContainers[0]},
+					Containers:     []corev1.Container{{Name: resourceName + "-container", Image: "busybox:1.36"}},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					InitContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "log-shipper",
+							Ready:        true,
+							RestartCount: 1,
+							State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+						},
+						{
+							Name:  "migrate",
+							State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+						},
+					},
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  resourceName + "-container",
+						Ready: true,
+						State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					}},
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}, job)).To(Succeed())
+			job.Status.Active = 1
+			Expect(controllerReconciler.Status().Update(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Containers).To(HaveLen(3))
+			sidecarStatus, initStatus, mainStatus := eva.Status.Containers[0], eva.Status.Containers[1], eva.Status.Containers[2]
+			Expect(sidecarStatus.Name).To(Equal("log-shipper"))
+			Expect(sidecarStatus.Type).To(Equal(geofrontv1alpha1.EvaContainerTypeSidecar))
+			Expect(sidecarStatus.State).To(Equal("Running"))
+			Expect(sidecarStatus.Ready).To(BeTrue())
+			Expect(sidecarStatus.RestartCount).To(Equal(int32(1)))
+			Expect(initStatus.Name).To(Equal("migrate"))
+			Expect(initStatus.Type).To(Equal(geofrontv1alpha1.EvaContainerTypeInit))
+			Expect(initStatus.State).To(Equal("Terminated"))
+			Expect(initStatus.Reason).To(Equal("Completed"))
+			Expect(mainStatus.Type).To(Equal(geofrontv1alpha1.EvaContainerTypeMain))
+			Expect(mainStatus.State).To(Equal("Running"))
+		})
+	})
+
+	Context("When an Eva runs an Indexed Job", func() {
+		const resourceName = "test-indexed"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		indexed := func(spec *geofrontv1alpha1.EvaSpec) {
+			spec.Parallelism = ptr.To(int32(2))
+			spec.Completions = ptr.To(int32(3))
+			spec.CompletionMode = kbatch.IndexedCompletion
+		}
+
+		It("should create the Job with a headless Service for its pods", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				indexed(spec)
+				spec.BackoffLimitPerIndex = ptr.To(int32(1))
+				spec.SuccessPolicy = &kbatch.SuccessPolicy{Rules: []kbatch.SuccessPolicyRule{{
+					SucceededIndexes: ptr.To("0"),
+				}}}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Parallelism).To(HaveValue(Equal(int32(2))))
+			Expect(job.Spec.Completions).To(HaveValue(Equal(int32(3))))
+			Expect(job.Spec.CompletionMode).To(HaveValue(Equal(kbatch.IndexedCompletion)))
+			Expect(job.Spec.SuccessPolicy.Rules).To(HaveLen(1))
+			Expect(job.Spec.BackoffLimitPerIndex).To(HaveValue(Equal(int32(1))))
+			Expect(job.Spec.BackoffLimit).To(HaveValue(Equal(int32(math.MaxInt32))))
+			Expect(job.Spec.Template.Spec.Subdomain).To(Equal(resourceName + "-service"))
+
+			service := &corev1.Service{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-service", Namespace: "default"}, service)).To(Succeed())
+			Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+			Expect(service.Spec.PublishNotReadyAddresses).To(BeTrue())
+			Expect(service.Spec.Selector).To(Equal(job.Spec.Template.Labels))
+		})
+
+		It("should report the completed indexes until all of them succeeded", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, indexed))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			By("Completing two of the three indexes")
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			job.Status.Active = 1
+			job.Status.Succeeded = 2
+			job.Status.CompletedIndexes = "0,2"
+			Expect(controllerReconciler.Status().Update(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseRunning))
+			Expect(eva.Status.Progress).To(Equal("2/3 completed"))
+			Expect(eva.Status.CompletedIndexes).To(Equal("0,2"))
+			Expect(meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionProgressing)).Message).
+				To(Equal("The Job is running (2/3 completed)."))
+
+			By("Completing the last index")
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			job.Status.Active = 0
+			job.Status.Succeeded = 3
+			job.Status.CompletedIndexes = "0-2"
+			Expect(controllerReconciler.Status().Update(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseSucceeded))
+			Expect(eva.Status.Progress).To(Equal("3/3 completed"))
+		})
+
+		It("should scale the running Job in place when parallelism changes", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, indexed))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			specHash := eva.Status.SpecHash
+			eva.Spec.Parallelism = ptr.To(int32(3))
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Parallelism).To(HaveValue(Equal(int32(3))))
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.SpecHash).To(Equal(specHash))
+		})
+	})
+
+	Context("When a Deployment-mode Eva sets probes", func() {
+		const resourceName = "test-probes"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		deploymentName := types.NamespacedName{Name: resourceName + "-deployment", Namespace: "default"}
+
+		It("should probe the container and finish the rollout only once its pods are ready", func() {
+			readiness := &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("http")},
+				},
+				PeriodSeconds: 5,
+			}
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Mode = geofrontv1alpha1.EvaModeDeployment
+				spec.Replicas = ptr.To(int32(2))
+				spec.Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}
+				spec.Probes = &geofrontv1alpha1.EvaProbes{
+					Readiness: readiness,
+					Liveness: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(8080)}},
+					},
+				}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			deployment := &appsv1.Deployment{}
+			Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.ReadinessProbe).To(Equal(readiness))
+			Expect(container.LivenessProbe.TCPSocket).NotTo(BeNil())
+			Expect(container.StartupProbe).To(BeNil())
+
+			setStatus := func(ready, available int32) {
+				Expect(controllerReconciler.Get(ctx, deploymentName, deployment)).To(Succeed())
+				deployment.Status = appsv1.DeploymentStatus{
+					ObservedGeneration: deployment.Generation,
+					Replicas:           2,
+					UpdatedReplicas:    2,
+					ReadyReplicas:      ready,
+					AvailableReplicas:  available,
+				}
+				Expect(controllerReconciler.Status().Update(ctx, deployment)).To(Succeed())
+				reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			}
+
+			By("Waiting for the readiness probe of the second pod")
+			setStatus(1, 1)
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionAvailable)).Reason).To(Equal("RollingOut"))
+			Expect(meta.IsStatusConditionTrue(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionProgressing))).To(BeTrue())
+
+			By("Passing the readiness probe on both pods")
+			setStatus(2, 2)
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			available := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionAvailable))
+			Expect(available.Status).To(Equal(metav1.ConditionTrue))
+			Expect(available.Reason).To(Equal("DeploymentAvailable"))
+			Expect(available.Message).To(ContainSubstring("2 ready"))
+			Expect(meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionReady)).Status).To(Equal(metav1.ConditionTrue))
+		})
+	})
+
+	Context("When an Eva sets scheduling constraints", func() {
+		It("should apply them to the Job, Deployment and CronJob pods", func() {
+			eva := testEva("test-scheduling", func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Schedule = &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}
+				spec.Scheduling = geofrontv1alpha1.EvaSchedulingSpec{
+					NodeSelector: map[string]string{"nerv.com/pool": "cage-07"},
+					Tolerations:  []corev1.Toleration{{Key: "nerv.com/eva", Operator: corev1.TolerationOpExists}},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+					}},
+					PriorityClassName: "eva-critical",
+					RuntimeClassName:  ptr.To("gvisor"),
+				}
+			})
+			controllerReconciler := newFakeReconciler()
+			job, err := controllerReconciler.desiredJob(eva, 1)
+			Expect(err).NotTo(HaveOccurred())
+			cronJob, err := controllerReconciler.desiredCronJob(eva)
+			Expect(err).NotTo(HaveOccurred())
+			deployment := controllerReconciler.desiredDeployment(eva)
+
+			for _, podSpec := range []corev1.PodSpec{
+				job.Spec.Template.Spec,
+				deployment.Spec.Template.Spec,
+				cronJob.Spec.JobTemplate.Spec.Template.Spec,
+			} {
+				Expect(podSpec.NodeSelector).To(HaveKeyWithValue("nerv.com/pool", "cage-07"))
+				Expect(podSpec.Tolerations).To(HaveLen(1))
+				Expect(podSpec.PriorityClassName).To(Equal("eva-critical"))
+				Expect(podSpec.RuntimeClassName).To(HaveValue(Equal("gvisor")))
+				Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+				Expect(podSpec.TopologySpreadConstraints[0].LabelSelector.MatchLabels).To(HaveKeyWithValue("eva-name", "test-scheduling"))
+			}
+			Expect(eva.Spec.Scheduling.TopologySpreadConstraints[0].LabelSelector).To(BeNil())
+		})
+	})
+
+	Context("When an Eva runs in a namespace enforcing Pod Security", func() {
+		const resourceName = "test-pod-security"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "restricted"}
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "restricted",
+			Labels: map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+		}}
+		restrictedEva := func(mutate func(*geofrontv1alpha1.EvaSpec)) *geofrontv1alpha1.Eva {
+			eva := testEva(resourceName, mutate)
+			eva.Namespace = namespace.Name
+			metav1.SetMetaDataAnnotation(&eva.ObjectMeta, geofrontv1alpha1.EvaRestrictedDefaultsAnnotation, "true")
+			return eva
+		}
+
+		It("should run the Job with restricted defaults", func() {
+			controllerReconciler := newFakeReconciler(namespace.DeepCopy(), restrictedEva(nil))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job", Namespace: "restricted"}, job)).To(Succeed())
+			podSpec := job.Spec.Template.Spec
+			Expect(podSpec.SecurityContext.RunAsNonRoot).To(HaveValue(BeTrue()))
+			Expect(podSpec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+			container := podSpec.Containers[0]
+			Expect(container.SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+			Expect(container.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")))
+			Expect(container.SecurityContext.ReadOnlyRootFilesystem).To(BeNil())
+			Expect(podSpec.Volumes).To(BeEmpty())
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPodSecurityAdmitted))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("Admitted"))
+		})
+
+		It("should mount a writable /tmp where the root filesystem is read-only", func() {
+			controllerReconciler := newFakeReconciler(namespace.DeepCopy(), restrictedEva(func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.SecurityContext = &geofrontv1alpha1.EvaSecurityContext{
+					Container: &corev1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true)},
+				}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job", Namespace: "restricted"}, job)).To(Succeed())
+			container := job.Spec.Template.Spec.Containers[0]
+			Expect(container.SecurityContext.ReadOnlyRootFilesystem).To(HaveValue(BeTrue()))
+			Expect(container.SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+			Expect(container.VolumeMounts).To(ContainElement(HaveField("MountPath", "/tmp")))
+		})
+
+		It("should explain why an override would be rejected", func() {
+			controllerReconciler := newFakeReconciler(namespace.DeepCopy(), restrictedEva(func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.SecurityContext = &geofrontv1alpha1.EvaSecurityContext{
+					Container: &corev1.SecurityContext{
+						Privileged:             ptr.To(true),
+						ReadOnlyRootFilesystem: ptr.To(false),
+					},
+				}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPodSecurityAdmitted))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("PodSecurityViolation"))
+			Expect(condition.Message).To(ContainSubstring("must not be privileged"))
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job", Namespace: "restricted"}, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("should not count the defaults towards the spec hash", func() {
+			controllerReconciler := newFakeReconciler()
+			job, err := controllerReconciler.desiredJob(restrictedEva(nil), 1)
+			Expect(err).NotTo(HaveOccurred())
+			job.Spec.Template.Spec.SecurityContext = nil
+			job.Spec.Template.Spec.Containers[0].SecurityContext = nil
+			job.Spec.Template.Spec.Containers[0].VolumeMounts = nil
+			job.Spec.Template.Spec.Volumes = nil
+			job.Spec.Suspend = nil
+			hash, err := computeSpecHash(job.Spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Annotations[specHashAnnotation]).To(Equal(hash))
+		})
+
+		It("should record the defaults on new Evas only", func() {
+			created := testEva(resourceName, nil)
+			existing := testEva(resourceName+"-existing", nil)
+			existing.Finalizers = []string{evaFinalizer}
+			controllerReconciler := newFakeReconciler(created, existing)
+			reconcileTimes(ctx, controllerReconciler, types.NamespacedName{Name: created.Name, Namespace: "default"}, 2)
+			reconcileTimes(ctx, controllerReconciler, types.NamespacedName{Name: existing.Name, Namespace: "default"}, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: created.Name, Namespace: "default"}, eva)).To(Succeed())
+			Expect(eva.Annotations).To(HaveKeyWithValue(geofrontv1alpha1.EvaRestrictedDefaultsAnnotation, "true"))
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: existing.Name, Namespace: "default"}, eva)).To(Succeed())
+			Expect(eva.Annotations).NotTo(HaveKey(geofrontv1alpha1.EvaRestrictedDefaultsAnnotation))
+		})
+
+		It("should leave every workload of Evas created before the defaults without them", func() {
+			controllerReconciler := newFakeReconciler()
+			for _, mode := range []geofrontv1alpha1.EvaMode{geofrontv1alpha1.EvaModeJob, geofrontv1alpha1.EvaModeDeployment, geofrontv1alpha1.EvaModeCronJob} {
+				eva := testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+					spec.Mode = mode
+					spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "busybox:1.36"}}
+				})
+				eva.Finalizers = []string{evaFinalizer}
+				podSpec, err := controllerReconciler.desiredPodSpec(eva)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(podSpec.SecurityContext).To(BeNil())
+				Expect(podSpec.Containers[0].SecurityContext).To(BeNil())
+				Expect(podSpec.InitContainers[0].SecurityContext).To(BeNil())
+			}
+		})
+	})
+
+	Context("When an Eva requests storage", func() {
+		const resourceName = "test-storage"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		claimName := types.NamespacedName{Name: resourceName + "-storage", Namespace: "default"}
+
+		withStorage := func(reclaimPolicy geofrontv1alpha1.EvaStorageReclaimPolicy) func(*geofrontv1alpha1.EvaSpec) {
+			return func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Storage = &geofrontv1alpha1.EvaStorage{
+					Size:          resource.MustParse("1Gi"),
+					MountPath:     "/data",
+					ReclaimPolicy: reclaimPolicy,
+				}
+			}
+		}
+
+		It("should provision a claim, mount it in the Job and expand it", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withStorage(geofrontv1alpha1.EvaStorageReclaimPolicyDelete)))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(controllerReconciler.Get(ctx, claimName, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", claimName.Name)))
+			Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      geofrontv1alpha1.EvaStorageVolumeName,
+				MountPath: "/data",
+			}))
+
+			By("Requesting more storage")
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			eva.Spec.Storage.Size = resource.MustParse("2Gi")
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, claimName, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+		})
+
+		DescribeTable("keeping the claim on deletion",
+			func(reclaimPolicy geofrontv1alpha1.EvaStorageReclaimPolicy, retained bool) {
+				controllerReconciler := newFakeReconciler(testEva(resourceName, withStorage(reclaimPolicy)))
+				reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+				eva := &geofrontv1alpha1.Eva{}
+				Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+				Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+				reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+				pvc := &corev1.PersistentVolumeClaim{}
+				Expect(controllerReconciler.Get(ctx, claimName, pvc)).To(Succeed())
+				if retained {
+					Expect(pvc.OwnerReferences).To(BeEmpty())
+				} else {
+					Expect(pvc.OwnerReferences).To(HaveLen(1))
+				}
+			},
+			Entry("retained with the Retain policy", geofrontv1alpha1.EvaStorageReclaimPolicyRetain, true),
+			Entry("left to garbage collection with the Delete policy", geofrontv1alpha1.EvaStorageReclaimPolicyDelete, false),
+		)
+	})
+
+	Context("When an Eva creates its own ServiceAccount", func() {
+		const resourceName = "test-serviceaccount"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+		serviceAccountName := types.NamespacedName{Name: resourceName + "-serviceaccount", Namespace: "default"}
+		roleName := types.NamespacedName{Name: resourceName + "-role", Namespace: "default"}
+		roleBindingName := types.NamespacedName{Name: resourceName + "-rolebinding", Namespace: "default"}
+
+		permissions := []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"get", "list"},
+		}}
+		withPermissions := func(spec *geofrontv1alpha1.EvaSpec) {
+			spec.ServiceAccount = &geofrontv1alpha1.EvaServiceAccount{Create: true}
+			spec.Permissions = permissions
+		}
+
+		It("should bind a Role with its permissions and run the Job as the ServiceAccount", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPermissions))
+			controllerReconciler.PermissionsReviewed = true
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			Expect(controllerReconciler.Get(ctx, serviceAccountName, &corev1.ServiceAccount{})).To(Succeed())
+			role := &rbacv1.Role{}
+			Expect(controllerReconciler.Get(ctx, roleName, role)).To(Succeed())
+			Expect(role.Rules).To(Equal(permissions))
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(controllerReconciler.Get(ctx, roleBindingName, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Name).To(Equal(roleName.Name))
+			Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{
+				Kind: rbacv1.ServiceAccountKind, Name: serviceAccountName.Name, Namespace: "default",
+			}))
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(serviceAccountName.Name))
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPermissionsGranted))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("Narrowing the permissions")
+			eva.Spec.Permissions[0].Verbs = []string{"get"}
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, roleName, role)).To(Succeed())
+			Expect(role.Rules[0].Verbs).To(Equal([]string{"get"}))
+
+			By("Deleting the Eva")
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, serviceAccountName, &corev1.ServiceAccount{}))).To(BeTrue())
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, roleName, &rbacv1.Role{}))).To(BeTrue())
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, roleBindingName, &rbacv1.RoleBinding{}))).To(BeTrue())
+		})
+
+		It("should report permissions the controller may not grant", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPermissions))
+			controllerReconciler.PermissionsReviewed = true
+			controllerReconciler.Client = interceptor.NewClient(controllerReconciler.Client.(client.WithWatch), interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok {
+						return errors.NewForbidden(rbacv1.Resource("roles"), obj.GetName(), nil)
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPermissionsGranted))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Forbidden"))
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, roleBindingName, &rbacv1.RoleBinding{}))).To(BeTrue())
+		})
+
+		It("should grant no permissions when no webhook reviews them", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPermissions))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			Expect(controllerReconciler.Get(ctx, serviceAccountName, &corev1.ServiceAccount{})).To(Succeed())
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, roleName, &rbacv1.Role{}))).To(BeTrue())
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, roleBindingName, &rbacv1.RoleBinding{}))).To(BeTrue())
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPermissionsGranted))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("NotReviewed"))
+		})
+
+		It("should run the pods as an existing ServiceAccount without creating one", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.ServiceAccount = &geofrontv1alpha1.EvaServiceAccount{Name: "builder"}
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal("builder"))
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, serviceAccountName, &corev1.ServiceAccount{}))).To(BeTrue())
+		})
+	})
+
+	Context("When an Eva references image pull secrets", func() {
+		const resourceName = "test-pullsecret"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+		copyName := types.NamespacedName{Name: resourceName + "-nerv-registry", Namespace: "default"}
+
+		withPullSecrets := func(spec *geofrontv1alpha1.EvaSpec) {
+			spec.ImagePullSecret = "pilot-registry"
+			spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{
+				{Name: "pilot-registry"},
+				{Name: "mirror-registry"},
+				{Name: "nerv-registry", Shared: true},
+			}
+		}
+		newSecret := func(name, namespace string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{ShareablePullSecretLabel: "true"},
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+			}
+		}
+
+		It("should copy shared pull secrets and keep the copies in sync", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPullSecrets),
+				newSecret("pilot-registry", "default"),
+				newSecret("mirror-registry", "default"),
+				newSecret("nerv-registry", "nerv-system"))
+			controllerReconciler.PullSecretNamespace = "nerv-system"
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{
+				{Name: "pilot-registry"}, {Name: "mirror-registry"}, {Name: copyName.Name},
+			}))
+			secret := &corev1.Secret{}
+			Expect(controllerReconciler.Get(ctx, copyName, secret)).To(Succeed())
+			Expect(secret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+			Expect(secret.Data).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, []byte(`{"auths":{}}`)))
+			Expect(secret.Labels).To(HaveKeyWithValue("eva-name", resourceName))
+			Expect(secret.Labels).To(HaveKeyWithValue(ShareablePullSecretLabel, PullSecretCopyLabelValue))
+			Expect(isShareable(secret)).To(BeFalse())
+			Expect(metav1.IsControlledBy(secret, testEva(resourceName, nil))).To(BeTrue())
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPullSecretMissing))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+			By("Rotating the shared pull secret")
+			source := &corev1.Secret{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: "nerv-registry", Namespace: "nerv-system"}, source)).To(Succeed())
+			source.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"registry.nerv.com":{}}}`)
+			Expect(controllerReconciler.Update(ctx, source)).To(Succeed())
+			Expect(controllerReconciler.mapSharedPullSecretToEvas(ctx, source)).To(ConsistOf(reconcile.Request{NamespacedName: typeNamespacedName}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, copyName, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, []byte(`{"auths":{"registry.nerv.com":{}}}`)))
+
+			By("No longer referencing the shared pull secret")
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			eva.Spec.ImagePullSecrets = eva.Spec.ImagePullSecrets[:2]
+			Expect(controllerReconciler.Update(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, copyName, secret))).To(BeTrue())
+		})
+
+		It("should report missing pull secrets and look them up again", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPullSecrets), newSecret("pilot-registry", "default"))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(pullSecretPollInterval))
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPullSecretMissing))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(Equal("The image pull secrets mirror-registry, nerv-registry do not exist. " +
+				"No shared pull secret namespace is configured."))
+		})
+
+		It("should refuse to copy shared secrets that are not labelled registry credentials", func() {
+			opaque := newSecret("nerv-registry", "nerv-system")
+			opaque.Type = corev1.SecretTypeOpaque
+			opaque.Data = map[string][]byte{"password": []byte("magi")}
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPullSecrets),
+				newSecret("pilot-registry", "default"),
+				newSecret("mirror-registry", "default"),
+				opaque)
+			controllerReconciler.PullSecretNamespace = "nerv-system"
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			secret := &corev1.Secret{}
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, copyName, secret))).To(BeTrue())
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPullSecretMissing))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("SecretNotShareable"))
+			Expect(condition.Message).To(Equal("The shared pull secrets nerv-registry may not be copied. " +
+				"Shared pull secrets are copied from the namespace nerv-system " +
+				"when they hold registry credentials and are labelled geofront.nerv.com/shareable=true."))
+
+			By("Turning the source into labelled registry credentials")
+			Expect(controllerReconciler.Delete(ctx, opaque)).To(Succeed())
+			Expect(controllerReconciler.Create(ctx, newSecret("nerv-registry", "nerv-system"))).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(controllerReconciler.Get(ctx, copyName, secret)).To(Succeed())
+			Expect(secret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+
+			By("Removing the opt-in label from the source")
+			source := &corev1.Secret{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: "nerv-registry", Namespace: "nerv-system"}, source)).To(Succeed())
+			delete(source.Labels, ShareablePullSecretLabel)
+			Expect(controllerReconciler.Update(ctx, source)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, copyName, secret))).To(BeTrue())
+		})
+
+		It("should refuse an unlabelled source the cache does not hold", func() {
+			unlabelled := newSecret("nerv-registry", "nerv-system")
+			unlabelled.Labels = nil
+			controllerReconciler := newFakeReconciler(testEva(resourceName, withPullSecrets),
+				newSecret("pilot-registry", "default"),
+				newSecret("mirror-registry", "default"),
+				unlabelled)
+			controllerReconciler.PullSecretNamespace = "nerv-system"
+			// The cache only holds the labelled Secrets of the shared namespace
+			controllerReconciler.APIReader = controllerReconciler.Client
+			controllerReconciler.Client = interceptor.NewClient(controllerReconciler.Client.(client.WithWatch), interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if err := c.Get(ctx, key, obj, opts...); err != nil {
+						return err
+					}
+					if _, ok := obj.(*corev1.Secret); ok && key.Namespace == "nerv-system" && obj.GetLabels()[ShareablePullSecretLabel] == "" {
+						return errors.NewNotFound(corev1.Resource("secrets"), key.Name)
+					}
+					return nil
+				},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionPullSecretMissing))
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("SecretNotShareable"))
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, copyName, &corev1.Secret{}))).To(BeTrue())
+		})
+	})
+
+	Context("When an Eva pins its image to a digest", func() {
+		const resourceName = "test-digest"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+
+		var server *registrytest.Registry
+
+		BeforeEach(func() {
+			server = registrytest.New()
+			server.Username, server.Password = "shinji", "unit-01"
+			DeferCleanup(server.Close)
+		})
+
+		newReconciler := func(image string) *EvaReconciler {
+			auths := fmt.Sprintf(`{"auths":{%q:{"username":"shinji","password":"unit-01"}}}`, server.Host())
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.Image = image
+				spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{{Name: "nerv-registry"}}
+				spec.ResolveImageDigest = true
+			}), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nerv-registry", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(auths)},
+			})
+			controllerReconciler.ImageResolver = &registry.Resolver{Client: server.Client()}
+			return controllerReconciler
+		}
+
+		It("should run the Job by digest and keep the digest for the next run", func() {
+			digest := server.Push("geofront/eva", "01", []byte(`{"schemaVersion":2,"layers":[]}`))
+			image := server.Host() + "/geofront/eva:01"
+			controllerReconciler := newReconciler(image)
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(image + "@" + digest))
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.ResolvedImage).To(Equal(image + "@" + digest))
+			specHash := eva.Status.SpecHash
+
+			By("Moving the tag and running the Job again")
+			server.Push("geofront/eva", "01", []byte(`{"schemaVersion":2,"layers":[{}]}`))
+			Expect(controllerReconciler.Delete(ctx, job)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(image + "@" + digest))
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.SpecHash).To(Equal(specHash))
+		})
+
+		It("should report a tag the registry does not know and try again", func() {
+			controllerReconciler := newReconciler(server.Host() + "/geofront/eva:02")
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(imageResolutionRetryInterval))
+
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, jobName, &kbatch.Job{}))).To(BeTrue())
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhasePending))
+			condition := meta.FindStatusCondition(eva.Status.Conditions, string(geofrontv1alpha1.EvaConditionDegraded))
+			Expect(condition.Reason).To(Equal("ImageResolutionFailed"))
+			Expect(condition.Message).To(ContainSubstring("404"))
+		})
+	})
+
+	Context("When an Eva is deleted", func() {
+		const resourceName = "test-teardown"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		jobName := types.NamespacedName{Name: resourceName + "-job", Namespace: "default"}
+		archiveName := types.NamespacedName{Name: resourceName + "-archive-" + resourceName, Namespace: "default"}
+
+		It("should stop the Job and wait for its pods before removing the finalizer", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.DeletionPolicy = geofrontv1alpha1.EvaDeletionPolicyDelete
+			}), &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-pod",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "eva-controller", "eva-name": resourceName},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.Spec.Suspend).To(HaveValue(BeTrue()))
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(eva.Status.Phase).To(Equal(geofrontv1alpha1.EvaPhaseTerminating))
+
+			By("Terminating the last pod")
+			Expect(controllerReconciler.Delete(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-pod", Namespace: "default"},
+			})).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, typeNamespacedName, eva))).To(BeTrue())
+			archive := &corev1.ConfigMap{}
+			Expect(controllerReconciler.Get(ctx, archiveName, archive)).To(Succeed())
+			Expect(archive.Data).To(HaveKey("status.json"))
+			Expect(archive.Labels).To(HaveKeyWithValue(archiveLabel, "true"))
+		})
+
+		It("should archive Evas whose names leave no room for the archive suffix", func() {
+			longName := strings.Repeat("a", 207) + "." + strings.Repeat("b", 42)
+			eva := testEva(longName, nil)
+			eva.UID = "0b7c3a4e-5f6d-4e2a-9c1b-8d7e6f5a4b3c"
+			name := strings.Repeat("a", 207) + "-archive-" + string(eva.UID)
+			Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+
+			controllerReconciler := newFakeReconciler(eva)
+			longNamespacedName := types.NamespacedName{Name: longName, Namespace: "default"}
+			reconcileTimes(ctx, controllerReconciler, longNamespacedName, 2)
+			Expect(controllerReconciler.Get(ctx, longNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, longNamespacedName, 1)
+
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, longNamespacedName, eva))).To(BeTrue())
+			archive := &corev1.ConfigMap{}
+			Expect(controllerReconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, archive)).To(Succeed())
+			Expect(archive.Data).To(HaveKey("status.json"))
+		})
+
+		It("should not archive over a ConfigMap it does not own", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, nil), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: archiveName.Name, Namespace: "default"},
+				Data:       map[string]string{"owner": "someone else"},
+			})
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, typeNamespacedName, eva))).To(BeTrue())
+			configMap := &corev1.ConfigMap{}
+			Expect(controllerReconciler.Get(ctx, archiveName, configMap)).To(Succeed())
+			Expect(configMap.Data).To(Equal(map[string]string{"owner": "someone else"}))
+		})
+
+		It("should collect archives once their TTL has passed", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, nil))
+			controllerReconciler.ArchiveTTL = time.Hour
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			archive := &corev1.ConfigMap{}
+			Expect(controllerReconciler.Get(ctx, archiveName, archive)).To(Succeed())
+			Expect(archive.Annotations).To(HaveKey(archiveExpiresAnnotation))
+
+			Expect(controllerReconciler.deleteExpiredArchives(ctx, time.Now(), GinkgoLogr)).To(Succeed())
+			Expect(controllerReconciler.Get(ctx, archiveName, archive)).To(Succeed())
+
+			Expect(controllerReconciler.deleteExpiredArchives(ctx, time.Now().Add(2*time.Hour), GinkgoLogr)).To(Succeed())
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, archiveName, archive))).To(BeTrue())
+		})
+
+		It("should detach the Job when the deletion policy is Orphan", func() {
+			controllerReconciler := newFakeReconciler(testEva(resourceName, func(spec *geofrontv1alpha1.EvaSpec) {
+				spec.DeletionPolicy = geofrontv1alpha1.EvaDeletionPolicyOrphan
+			}))
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 2)
+
+			eva := &geofrontv1alpha1.Eva{}
+			Expect(controllerReconciler.Get(ctx, typeNamespacedName, eva)).To(Succeed())
+			Expect(controllerReconciler.Delete(ctx, eva)).To(Succeed())
+			reconcileTimes(ctx, controllerReconciler, typeNamespacedName, 1)
+
+			job := &kbatch.Job{}
+			Expect(controllerReconciler.Get(ctx, jobName, job)).To(Succeed())
+			Expect(job.OwnerReferences).To(BeEmpty())
+			Expect(job.Spec.Suspend).To(HaveValue(BeFalse()))
+			Expect(errors.IsNotFound(controllerReconciler.Get(ctx, typeNamespacedName, eva))).To(BeTrue())
+		})
+	})
 })
+
+// testEva returns an Eva in the default namespace running busybox, with the
+// spec fields a test exercises set by mutate
+func testEva(name string, mutate func(*geofrontv1alpha1.EvaSpec)) *geofrontv1alpha1.Eva {
+	eva := &geofrontv1alpha1.Eva{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec:       geofrontv1alpha1.EvaSpec{Image: "busybox:1.36"},
+	}
+	if mutate != nil {
+		mutate(&eva.Spec)
+	}
+	return eva
+}
+
+// newFakeReconciler returns an EvaReconciler backed by a fake client carrying
+// the same owner indexes the manager registers in SetupWithManager. It needs
+// no API server, so specs using it run without envtest.
+func newFakeReconciler(objs ...client.Object) *EvaReconciler {
+	Expect(geofrontv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&geofrontv1alpha1.Eva{}).
+		WithIndex(&kbatch.Job{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&corev1.Service{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&appsv1.Deployment{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&kbatch.CronJob{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&corev1.PersistentVolumeClaim{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&corev1.ServiceAccount{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&rbacv1.Role{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&rbacv1.RoleBinding{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&corev1.Secret{}, ownerKey, common.OwnerIndexFunc("Eva")).
+		WithIndex(&geofrontv1alpha1.Eva{}, sharedPullSecretKey, indexSharedPullSecrets).
+		WithIndex(&kbatch.Job{}, cronJobOwnerKey, common.OwnerIndexFunc("CronJob")).
+		Build()
+	return &EvaReconciler{
+		Client: fakeClient,
+		Scheme: fakeClient.Scheme(),
+	}
+}
+
+func reconcileTimes(ctx context.Context, r *EvaReconciler, name types.NamespacedName, times int) {
+	for range times {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
@@ -0,0 +1,206 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	kbatch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// maxRecentRuns bounds the number of scheduled runs summarised in the Eva status
+const maxRecentRuns = 10
+
+const defaultSuccessfulJobsHistoryLimit = 3
+const defaultFailedJobsHistoryLimit = 1
+
+func (r *EvaReconciler) reconcileCronJob(ctx context.Context, eva *v1alpha1.Eva, cronJobState cronJobState, logger logr.Logger) (*v1alpha1.EvaStatus, error) {
+	newStatus := &v1alpha1.EvaStatus{}
+	if !cronJobState.Exists {
+		logger.Info("Creating CronJob for Eva", "Eva.Name", eva.Name, "paused", eva.Spec.Paused)
+		if err := r.createCronJob(ctx, eva, logger); err != nil {
+			return nil, err
+		}
+		if eva.Spec.Paused {
+			newStatus.Phase = v1alpha1.EvaPhasePaused
+			newStatus.Conditions = pausedConditions(eva, "The CronJob has been created suspended.")
+			return newStatus, nil
+		}
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"CronJobCreated", "The CronJob has been created.")
+		return newStatus, nil
+	}
+
+	if err := r.updateCronJobIfChanged(ctx, eva, logger); err != nil {
+		return nil, err
+	}
+	setScheduleStatus(newStatus, cronJobState)
+	if eva.Spec.Paused {
+		newStatus.Phase = v1alpha1.EvaPhasePaused
+		newStatus.Conditions = pausedConditions(eva, "The CronJob is suspended.")
+		return newStatus, nil
+	}
+
+	// A scheduled Eva stays Running for as long as its schedule is active
+	newStatus.Phase = v1alpha1.EvaPhaseRunning
+	lastRun := lastFinishedRun(cronJobState.Runs)
+	switch {
+	case cronJobState.Active > 0:
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"JobRunning", fmt.Sprintf("%d scheduled Job(s) running.", cronJobState.Active))
+	case lastRun != nil && lastRun.Phase == v1alpha1.EvaPhaseFailed:
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse,
+			"LastRunFailed", fmt.Sprintf("The last scheduled Job %s failed.", lastRun.JobName))
+	default:
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse,
+			"Scheduled", "Waiting for the next scheduled run.")
+	}
+	return newStatus, nil
+}
+
+// lastFinishedRun returns the most recent run that succeeded or failed
+func lastFinishedRun(runs []v1alpha1.EvaRunSummary) *v1alpha1.EvaRunSummary {
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Phase != v1alpha1.EvaPhaseRunning {
+			return &runs[i]
+		}
+	}
+	return nil
+}
+
+// setScheduleStatus records the schedule times and recent runs of the CronJob.
+// RecentRuns is never nil so that an empty history is still written back.
+func setScheduleStatus(status *v1alpha1.EvaStatus, cronJobState cronJobState) {
+	status.LastScheduleTime = cronJobState.LastScheduleTime
+	status.LastSuccessfulTime = cronJobState.LastSuccessfulTime
+	status.RecentRuns = append([]v1alpha1.EvaRunSummary{}, cronJobState.Runs...)
+}
+
+func (r *EvaReconciler) desiredCronJob(eva *v1alpha1.Eva) (*kbatch.CronJob, error) {
+	job, err := r.desiredJob(eva, 1)
+	if err != nil {
+		return nil, err
+	}
+	// The schedule is suspended instead of the Jobs it starts, and the
+	// bookkeeping annotations only describe Jobs the Eva owns directly
+	job.Spec.Suspend = nil
+	job.Annotations = nil
+
+	schedule := eva.Spec.Schedule
+	successfulLimit, failedLimit := int32(defaultSuccessfulJobsHistoryLimit), int32(defaultFailedJobsHistoryLimit)
+	if schedule.SuccessfulJobsHistoryLimit != nil {
+		successfulLimit = *schedule.SuccessfulJobsHistoryLimit
+	}
+	if schedule.FailedJobsHistoryLimit != nil {
+		failedLimit = *schedule.FailedJobsHistoryLimit
+	}
+	concurrencyPolicy := schedule.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = kbatch.ForbidConcurrent
+	}
+	opts := []CronJobOption{
+		WithCronJobLabels(r.generateLabels(eva, nil)),
+		WithCronJobSchedule(schedule.Cron, schedule.TimeZone),
+		WithCronJobConcurrencyPolicy(concurrencyPolicy),
+		WithCronJobHistoryLimits(successfulLimit, failedLimit),
+		WithCronJobSuspend(eva.Spec.Paused),
+		WithCronJobJobTemplate(job),
+	}
+	if schedule.StartingDeadlineSeconds != nil {
+		opts = append(opts, WithCronJobStartingDeadlineSeconds(*schedule.StartingDeadlineSeconds))
+	}
+	return buildCronJob(fmt.Sprintf("%s-cronjob", eva.Name), eva.Namespace, opts...), nil
+}
+
+func (r *EvaReconciler) createCronJob(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	desired, err := r.desiredCronJob(eva)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
+		logger.Error(err, "failed to set controller reference: ", "error", err)
+		return err
+	}
+	if err := r.Create(ctx, desired); err != nil {
+		logger.Error(err, "failed to create cronjob: ", "error", err)
+		return err
+	}
+
+	logger.Info("Created CronJob for Eva", "eva", eva.Name, "schedule", eva.Spec.Schedule.Cron)
+	return nil
+}
+
+// updateCronJobIfChanged updates the CronJob when the Eva spec no longer matches
+// its schedule or Job template. Runs already started keep their old template.
+func (r *EvaReconciler) updateCronJobIfChanged(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	existing, err := GetOwnedCronJob(ctx, r.Client, eva, ownerKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	desired, err := r.desiredCronJob(eva)
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(ownedCronJobSpec(&desired.Spec), ownedCronJobSpec(&existing.Spec)) {
+		return nil
+	}
+
+	existing.Spec = desired.Spec
+	logger.Info("Updating CronJob for Eva", "eva", eva.Name, "schedule", eva.Spec.Schedule.Cron, "suspend", eva.Spec.Paused)
+	return r.Update(ctx, existing)
+}
+
+// ownedCronJobSpec normalizes the pod template of a CronJob spec as
+// ownedPodTemplate does for Deployments. The API server defaults no other
+// field of the spec the controller leaves empty.
+func ownedCronJobSpec(spec *kbatch.CronJobSpec) *kbatch.CronJobSpec {
+	owned := spec.DeepCopy()
+	owned.JobTemplate.Spec.Template = *ownedPodTemplate(&spec.JobTemplate.Spec.Template)
+	return owned
+}
+
+// suspendScheduledJobs suspends the CronJob and the Jobs it started that are still running
+func (r *EvaReconciler) suspendScheduledJobs(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	cronJob, err := GetOwnedCronJob(ctx, r.Client, eva, ownerKey)
+	if err != nil || cronJob == nil {
+		return err
+	}
+	if cronJob.Spec.Suspend == nil || !*cronJob.Spec.Suspend {
+		patch := client.MergeFrom(cronJob.DeepCopy())
+		WithCronJobSuspend(true)(cronJob)
+		if err := r.Patch(ctx, cronJob, patch); err != nil {
+			logger.Error(err, "failed to suspend cronjob: ", "error", err)
+			return err
+		}
+		logger.Info("Suspended CronJob for teardown", "eva", eva.Name, "cronJob", cronJob.Name)
+	}
+
+	jobs, err := r.listScheduledJobs(ctx, cronJob)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if isJobFinished(job) || (job.Spec.Suspend != nil && *job.Spec.Suspend) {
+			continue
+		}
+		patch := client.MergeFrom(job.DeepCopy())
+		WithJobSuspend(true)(job)
+		if err := r.Patch(ctx, job, patch); err != nil {
+			logger.Error(err, "failed to suspend scheduled job: ", "error", err)
+			return err
+		}
+		logger.Info("Suspended scheduled Job for teardown", "eva", eva.Name, "job", job.Name)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package eva
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/dayaliuzzo/Smooth-Operator/internal/registry"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resolvedImageAnnotation records on a Job the image pinned to a digest it runs
+const resolvedImageAnnotation = "geofront.nerv.com/resolved-image"
+
+// imageResolutionRetryInterval is how long to wait before asking the registry again
+const imageResolutionRetryInterval = 30 * time.Second
+
+// imageResolutionError is returned by createJob when the image of the Eva
+// could not be pinned to a digest
+type imageResolutionError struct {
+	err error
+}
+
+func (e *imageResolutionError) Error() string { return e.err.Error() }
+
+func (e *imageResolutionError) Unwrap() error { return e.err }
+
+// pinnedImage returns the image a new Job of the Eva runs: the image pinned on
+// an earlier run as long as spec.image is unchanged, so that retries and
+// reruns use the same bits, or else the digest its tag points to now
+func (r *EvaReconciler) pinnedImage(ctx context.Context, eva *v1alpha1.Eva) (string, error) {
+	if strings.HasPrefix(eva.Status.ResolvedImage, eva.Spec.Image+"@") {
+		return eva.Status.ResolvedImage, nil
+	}
+	credentials, err := r.pullCredentials(ctx, eva)
+	if err != nil {
+		return "", err
+	}
+	resolver := r.ImageResolver
+	if resolver == nil {
+		resolver = &registry.Resolver{}
+	}
+	return resolver.Resolve(ctx, eva.Spec.Image, credentials)
+}
+
+// pullCredentials reads the registry credentials of the Eva's pull secrets.
+// Missing secrets are skipped, as they are reported on PullSecretMissing.
+func (r *EvaReconciler) pullCredentials(ctx context.Context, eva *v1alpha1.Eva) (registry.Credentials, error) {
+	var secrets []corev1.Secret
+	for _, name := range imagePullSecretNames(eva) {
+		secret := &corev1.Secret{}
+		err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: eva.Namespace, Name: name}, secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, *secret)
+	}
+	return registry.CredentialsFromSecrets(secrets)
+}
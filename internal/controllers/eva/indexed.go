@@ -0,0 +1,61 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	kbatch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// headlessServiceName names the Service giving the pods of an Indexed Job
+// their stable DNS names, <job>-<index>.<service>
+func headlessServiceName(eva *v1alpha1.Eva) string {
+	return fmt.Sprintf("%s-service", eva.Name)
+}
+
+func (r *EvaReconciler) desiredHeadlessService(eva *v1alpha1.Eva) *corev1.Service {
+	return buildService(headlessServiceName(eva), eva.Namespace,
+		WithServiceLabels(r.generateLabels(eva, nil)),
+		WithServiceSelector(r.generateLabels(eva, nil)),
+		WithServiceHeadless())
+}
+
+// reconcileHeadlessService creates the headless Service of an Indexed Job so
+// its pods can find each other, and removes it once the Eva is no longer indexed
+func (r *EvaReconciler) reconcileHeadlessService(ctx context.Context, eva *v1alpha1.Eva, serviceState serviceState, logger logr.Logger) error {
+	if eva.Spec.CompletionMode != kbatch.IndexedCompletion {
+		if !serviceState.Exists {
+			return nil
+		}
+		existing, err := GetOwnedService(ctx, r.Client, eva, ownerKey)
+		if err != nil || existing == nil {
+			return err
+		}
+		if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete service: ", "error", err)
+			return err
+		}
+		logger.Info("Deleted headless Service for Eva", "eva", eva.Name, "service", existing.Name)
+		return nil
+	}
+	if serviceState.Exists {
+		return nil
+	}
+
+	desired := r.desiredHeadlessService(eva)
+	if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
+		logger.Error(err, "failed to set controller reference: ", "error", err)
+		return err
+	}
+	if err := r.Create(ctx, desired); err != nil {
+		logger.Error(err, "failed to create service: ", "error", err)
+		return err
+	}
+	logger.Info("Created headless Service for Eva", "eva", eva.Name, "service", desired.Name)
+	return nil
+}
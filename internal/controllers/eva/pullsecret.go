@@ -0,0 +1,264 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// pullSecretPollInterval is how often missing pull secrets of the Eva's own
+// namespace are looked up again. They are not watched, as the controller only
+// caches the Secrets it copies.
+const pullSecretPollInterval = 30 * time.Second
+
+// sharedPullSecretKey indexes Evas by the shared pull secrets they copy
+const sharedPullSecretKey = ".spec.imagePullSecrets.shared"
+
+// ShareablePullSecretLabel opts a Secret of the shared pull secret namespace
+// in to being copied into the namespaces of the Evas referencing it
+const ShareablePullSecretLabel = "geofront.nerv.com/shareable"
+
+// PullSecretCopyLabelValue is the value of ShareablePullSecretLabel on the
+// copies, so that one selector caches both the sources and the copies made
+// in the shared pull secret namespace, and copies are never copied again
+const PullSecretCopyLabelValue = "copy"
+
+// sharedPullSecretName names the Eva's copy of a shared pull secret
+func sharedPullSecretName(eva *v1alpha1.Eva, name string) string {
+	return fmt.Sprintf("%s-%s", eva.Name, name)
+}
+
+// imagePullSecretNames returns the Secrets the Eva's pods pull their images with
+func imagePullSecretNames(eva *v1alpha1.Eva) []string {
+	var names []string
+	if eva.Spec.ImagePullSecret != "" {
+		names = append(names, eva.Spec.ImagePullSecret)
+	}
+	for _, secret := range eva.Spec.ImagePullSecrets {
+		name := secret.Name
+		if secret.Shared {
+			name = sharedPullSecretName(eva, secret.Name)
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// indexSharedPullSecrets lists the shared pull secrets an Eva copies
+func indexSharedPullSecrets(obj client.Object) []string {
+	eva, ok := obj.(*v1alpha1.Eva)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, secret := range eva.Spec.ImagePullSecrets {
+		if secret.Shared {
+			names = append(names, secret.Name)
+		}
+	}
+	return names
+}
+
+// uncachedReader reads the objects kept out of the cache, such as the Secrets
+// of the Eva's namespace and the archives of deleted Evas
+func (r *EvaReconciler) uncachedReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// isShareable reports whether a Secret of the shared pull secret namespace may
+// be copied: only registry credentials that opted in are
+func isShareable(secret *corev1.Secret) bool {
+	return secret.Labels[ShareablePullSecretLabel] == "true" &&
+		(secret.Type == corev1.SecretTypeDockerConfigJson || secret.Type == corev1.SecretTypeDockercfg)
+}
+
+// getPullSecretState looks up the pull secrets the Eva references
+func (r *EvaReconciler) getPullSecretState(ctx context.Context, eva *v1alpha1.Eva) (pullSecretState, error) {
+	pullSecretState := pullSecretState{}
+	exists := func(reader client.Reader, namespace, name string) (bool, error) {
+		err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Secret{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	// Sources are read past the cache, which only holds labelled ones, so
+	// that a source missing the label is refused rather than reported missing
+	shared := func(name string) (bool, bool, error) {
+		secret := &corev1.Secret{}
+		err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: r.PullSecretNamespace, Name: name}, secret)
+		if apierrors.IsNotFound(err) {
+			return false, false, nil
+		}
+		return err == nil, isShareable(secret), err
+	}
+
+	if eva.Spec.ImagePullSecret != "" {
+		found, err := exists(r.uncachedReader(), eva.Namespace, eva.Spec.ImagePullSecret)
+		if err != nil {
+			return pullSecretState, err
+		}
+		if !found {
+			pullSecretState.Missing = append(pullSecretState.Missing, eva.Spec.ImagePullSecret)
+		}
+	}
+	for _, secret := range eva.Spec.ImagePullSecrets {
+		found, shareable := false, true
+		var err error
+		switch {
+		case !secret.Shared:
+			found, err = exists(r.uncachedReader(), eva.Namespace, secret.Name)
+		case r.PullSecretNamespace != "":
+			found, shareable, err = shared(secret.Name)
+		}
+		if err != nil {
+			return pullSecretState, err
+		}
+		switch {
+		case !found:
+			pullSecretState.Missing = append(pullSecretState.Missing, secret.Name)
+		case !shareable:
+			pullSecretState.Refused = append(pullSecretState.Refused, secret.Name)
+		}
+	}
+	return pullSecretState, nil
+}
+
+// reconcilePullSecrets copies the shared pull secrets of the Eva into its
+// namespace and keeps the copies in sync with their source, and reports pull
+// secrets that do not exist or may not be shared on the PullSecretMissing
+// condition
+func (r *EvaReconciler) reconcilePullSecrets(ctx context.Context, eva *v1alpha1.Eva, pullSecretState pullSecretState, logger logr.Logger) (*metav1.Condition, error) {
+	owned, err := GetOwnedSecrets(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	desired := map[string]bool{}
+	for _, secret := range eva.Spec.ImagePullSecrets {
+		if !secret.Shared || r.PullSecretNamespace == "" ||
+			slices.Contains(pullSecretState.Missing, secret.Name) || slices.Contains(pullSecretState.Refused, secret.Name) {
+			continue
+		}
+		source := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: r.PullSecretNamespace, Name: secret.Name}, source); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if !isShareable(source) {
+			continue
+		}
+		name := sharedPullSecretName(eva, secret.Name)
+		desired[name] = true
+		if err := r.syncPullSecret(ctx, eva, owned, name, source, logger); err != nil {
+			return nil, err
+		}
+	}
+	for i := range owned {
+		if desired[owned[i].Name] {
+			continue
+		}
+		if err := r.Delete(ctx, &owned[i]); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete secret: ", "error", err, "secret", owned[i].Name)
+			return nil, err
+		}
+		logger.Info("Deleted pull secret copy of Eva", "eva", eva.Name, "secret", owned[i].Name)
+	}
+
+	if len(imagePullSecretNames(eva)) == 0 &&
+		meta.FindStatusCondition(eva.Status.Conditions, string(v1alpha1.EvaConditionPullSecretMissing)) == nil {
+		return nil, nil
+	}
+	condition := &metav1.Condition{
+		Type:               string(v1alpha1.EvaConditionPullSecretMissing),
+		Status:             metav1.ConditionFalse,
+		Reason:             "SecretsFound",
+		Message:            "All image pull secrets exist.",
+		ObservedGeneration: eva.Generation,
+	}
+	var messages []string
+	if len(pullSecretState.Missing) > 0 {
+		condition.Reason = "SecretNotFound"
+		messages = append(messages, fmt.Sprintf("The image pull secrets %s do not exist.", strings.Join(pullSecretState.Missing, ", ")))
+	}
+	if len(pullSecretState.Refused) > 0 {
+		if condition.Reason != "SecretNotFound" {
+			condition.Reason = "SecretNotShareable"
+		}
+		messages = append(messages, fmt.Sprintf("The shared pull secrets %s may not be copied.", strings.Join(pullSecretState.Refused, ", ")))
+	}
+	if len(messages) == 0 {
+		return condition, nil
+	}
+	condition.Status = metav1.ConditionTrue
+	if slices.ContainsFunc(eva.Spec.ImagePullSecrets, func(secret v1alpha1.EvaImagePullSecret) bool { return secret.Shared }) {
+		if r.PullSecretNamespace == "" {
+			messages = append(messages, "No shared pull secret namespace is configured.")
+		} else {
+			messages = append(messages, fmt.Sprintf("Shared pull secrets are copied from the namespace %s "+
+				"when they hold registry credentials and are labelled %s=true.", r.PullSecretNamespace, ShareablePullSecretLabel))
+		}
+	}
+	condition.Message = strings.Join(messages, " ")
+	return condition, nil
+}
+
+// syncPullSecret creates the Eva's copy of a shared pull secret, or updates
+// it to the data of its source
+func (r *EvaReconciler) syncPullSecret(ctx context.Context, eva *v1alpha1.Eva, owned []corev1.Secret, name string, source *corev1.Secret, logger logr.Logger) error {
+	desired := buildSecret(name, eva.Namespace,
+		WithSecretLabels(r.generateLabels(eva, map[string]string{ShareablePullSecretLabel: PullSecretCopyLabelValue})),
+		WithSecretData(source.Type, source.Data))
+	i := slices.IndexFunc(owned, func(secret corev1.Secret) bool { return secret.Name == name })
+	if i < 0 {
+		return r.createOwned(ctx, eva, desired, logger)
+	}
+	existing := &owned[i]
+	if existing.Type == source.Type && equality.Semantic.DeepEqual(existing.Data, source.Data) {
+		return nil
+	}
+	// The type of a Secret is immutable
+	if existing.Type != source.Type {
+		if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete secret: ", "error", err, "secret", existing.Name)
+			return err
+		}
+		return r.createOwned(ctx, eva, desired, logger)
+	}
+	patch := client.MergeFrom(existing.DeepCopy())
+	existing.Data = source.Data
+	logger.Info("Updating pull secret copy for Eva", "eva", eva.Name, "secret", existing.Name)
+	return r.Patch(ctx, existing, patch)
+}
+
+// mapSharedPullSecretToEvas enqueues the Evas copying a shared pull secret
+func (r *EvaReconciler) mapSharedPullSecretToEvas(ctx context.Context, obj client.Object) []reconcile.Request {
+	if r.PullSecretNamespace == "" || obj.GetNamespace() != r.PullSecretNamespace {
+		return nil
+	}
+	evaList := &v1alpha1.EvaList{}
+	if err := r.List(ctx, evaList, client.MatchingFields{sharedPullSecretKey: obj.GetName()}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(evaList.Items))
+	for _, eva := range evaList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: eva.Namespace, Name: eva.Name}})
+	}
+	return requests
+}
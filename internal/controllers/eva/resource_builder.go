@@ -2,18 +2,32 @@ package eva
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
 
 	appsv1 "k8s.io/api/apps/v1"
 	kbatch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type (
-	JobOption        func(*kbatch.Job)
-	ServiceOption    func(*corev1.Service)
-	DeploymentOption func(*appsv1.Deployment)
+	JobOption            func(*kbatch.Job)
+	ServiceOption        func(*corev1.Service)
+	DeploymentOption     func(*appsv1.Deployment)
+	CronJobOption        func(*kbatch.CronJob)
+	PVCOption            func(*corev1.PersistentVolumeClaim)
+	ServiceAccountOption func(*corev1.ServiceAccount)
+	RoleOption           func(*rbacv1.Role)
+	RoleBindingOption    func(*rbacv1.RoleBinding)
+	SecretOption         func(*corev1.Secret)
 )
 
 func GetOwnedJob(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*kbatch.Job, error) {
@@ -26,7 +40,28 @@ func GetOwnedJob(ctx context.Context, c client.Client, owner client.Object, owne
 	if len(jobList.Items) == 0 {
 		return nil, nil
 	}
-	return &jobList.Items[0], nil
+	// A retried or replaced Job may linger while it is deleted, so prefer
+	// the newest Job that is not terminating
+	current := &jobList.Items[0]
+	for i := range jobList.Items[1:] {
+		job := &jobList.Items[i+1]
+		if newerJob(job, current) {
+			current = job
+		}
+	}
+	return current, nil
+}
+
+// newerJob reports whether a should be preferred over b as the current Job
+func newerJob(a, b *kbatch.Job) bool {
+	aTerminating, bTerminating := !a.DeletionTimestamp.IsZero(), !b.DeletionTimestamp.IsZero()
+	if aTerminating != bTerminating {
+		return bTerminating
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
 }
 
 func GetOwnedService(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*corev1.Service, error) {
@@ -55,6 +90,146 @@ func GetOwnedDeployment(ctx context.Context, c client.Client, owner client.Objec
 	return &depList.Items[0], nil
 }
 
+func GetOwnedCronJob(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*kbatch.CronJob, error) {
+	cronJobList := &kbatch.CronJobList{}
+	if err := c.List(ctx, cronJobList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	if len(cronJobList.Items) == 0 {
+		return nil, nil
+	}
+	return &cronJobList.Items[0], nil
+}
+
+func GetOwnedPVC(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	if len(pvcList.Items) == 0 {
+		return nil, nil
+	}
+	return &pvcList.Items[0], nil
+}
+
+func GetOwnedServiceAccount(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*corev1.ServiceAccount, error) {
+	saList := &corev1.ServiceAccountList{}
+	if err := c.List(ctx, saList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	if len(saList.Items) == 0 {
+		return nil, nil
+	}
+	return &saList.Items[0], nil
+}
+
+func GetOwnedRole(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*rbacv1.Role, error) {
+	roleList := &rbacv1.RoleList{}
+	if err := c.List(ctx, roleList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	if len(roleList.Items) == 0 {
+		return nil, nil
+	}
+	return &roleList.Items[0], nil
+}
+
+func GetOwnedRoleBinding(ctx context.Context, c client.Client, owner client.Object, ownerKey string) (*rbacv1.RoleBinding, error) {
+	bindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, bindingList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	if len(bindingList.Items) == 0 {
+		return nil, nil
+	}
+	return &bindingList.Items[0], nil
+}
+
+// GetOwnedSecrets returns every Secret owned by owner
+func GetOwnedSecrets(ctx context.Context, c client.Client, owner client.Object, ownerKey string) ([]corev1.Secret, error) {
+	secretList := &corev1.SecretList{}
+	if err := c.List(ctx, secretList,
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingFields{ownerKey: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	return secretList.Items, nil
+}
+
+func buildServiceAccount(name, namespace string, opts ...ServiceAccountOption) *corev1.ServiceAccount {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(serviceAccount)
+	}
+
+	return serviceAccount
+}
+
+func buildRole(name, namespace string, opts ...RoleOption) *rbacv1.Role {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(role)
+	}
+
+	return role
+}
+
+func buildRoleBinding(name, namespace string, opts ...RoleBindingOption) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(roleBinding)
+	}
+
+	return roleBinding
+}
+
+func buildSecret(name, namespace string, opts ...SecretOption) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(secret)
+	}
+
+	return secret
+}
+
 func buildJob(name, namespace string, opts ...JobOption) *kbatch.Job {
 	job := &kbatch.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -130,6 +305,50 @@ func buildDeployment(name, namespace string, opts ...DeploymentOption) *appsv1.D
 	return deployment
 }
 
+func buildCronJob(name, namespace string, opts ...CronJobOption) *kbatch.CronJob {
+	cronJob := &kbatch.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(cronJob)
+	}
+
+	return cronJob
+}
+
+func buildPVC(name, namespace string, opts ...PVCOption) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(pvc)
+	}
+
+	return pvc
+}
+
+// computeSpecHash returns a short, stable hash of obj, used to detect when the
+// spec a child resource was built from has changed
+func computeSpecHash(obj any) (string, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(data)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
+
 // WithJobImage sets the container image
 func WithJobImage(image string) JobOption {
 	return func(job *kbatch.Job) {
@@ -148,6 +367,69 @@ func WithJobCommand(command []string) JobOption {
 	}
 }
 
+// WithJobArgs sets the container arguments
+func WithJobArgs(args []string) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 && len(args) > 0 {
+			job.Spec.Template.Spec.Containers[0].Args = args
+		}
+	}
+}
+
+// WithJobEnv sets the container environment variables
+func WithJobEnv(env []corev1.EnvVar) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 && len(env) > 0 {
+			job.Spec.Template.Spec.Containers[0].Env = env
+		}
+	}
+}
+
+// WithJobEnvFrom sets the sources the container environment is populated from
+func WithJobEnvFrom(envFrom []corev1.EnvFromSource) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 && len(envFrom) > 0 {
+			job.Spec.Template.Spec.Containers[0].EnvFrom = envFrom
+		}
+	}
+}
+
+// WithJobWorkingDir sets the container working directory
+func WithJobWorkingDir(dir string) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 {
+			job.Spec.Template.Spec.Containers[0].WorkingDir = dir
+		}
+	}
+}
+
+// WithJobPorts sets the container ports
+func WithJobPorts(ports []corev1.ContainerPort) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 && len(ports) > 0 {
+			job.Spec.Template.Spec.Containers[0].Ports = ports
+		}
+	}
+}
+
+// WithJobVolumes sets the pod volumes
+func WithJobVolumes(volumes []corev1.Volume) JobOption {
+	return func(job *kbatch.Job) {
+		if len(volumes) > 0 {
+			job.Spec.Template.Spec.Volumes = volumes
+		}
+	}
+}
+
+// WithJobVolumeMounts sets the container volume mounts
+func WithJobVolumeMounts(mounts []corev1.VolumeMount) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 && len(mounts) > 0 {
+			job.Spec.Template.Spec.Containers[0].VolumeMounts = mounts
+		}
+	}
+}
+
 // WithJobContainerName sets the container name
 func WithJobContainerName(name string) JobOption {
 	return func(job *kbatch.Job) {
@@ -157,6 +439,15 @@ func WithJobContainerName(name string) JobOption {
 	}
 }
 
+// WithJobResources sets the container resource requests and limits
+func WithJobResources(resources corev1.ResourceRequirements) JobOption {
+	return func(job *kbatch.Job) {
+		if len(job.Spec.Template.Spec.Containers) > 0 {
+			job.Spec.Template.Spec.Containers[0].Resources = resources
+		}
+	}
+}
+
 // WithJobLabels sets metadata labels on both the Job and Pod template
 func WithJobLabels(labels map[string]string) JobOption {
 	return func(job *kbatch.Job) {
@@ -178,13 +469,24 @@ func WithJobLabels(labels map[string]string) JobOption {
 	}
 }
 
-// WithJobImagePullSecret adds an image pull secret
-func WithJobImagePullSecret(secretName string) JobOption {
+// WithJobAnnotations sets metadata annotations on the Job
+func WithJobAnnotations(annotations map[string]string) JobOption {
 	return func(job *kbatch.Job) {
-		if secretName != "" {
-			job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
-				{Name: secretName},
-			}
+		if job.Annotations == nil {
+			job.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			job.Annotations[k] = v
+		}
+	}
+}
+
+// WithJobImagePullSecrets adds image pull secrets
+func WithJobImagePullSecrets(secretNames ...string) JobOption {
+	return func(job *kbatch.Job) {
+		for _, secretName := range secretNames {
+			job.Spec.Template.Spec.ImagePullSecrets = append(job.Spec.Template.Spec.ImagePullSecrets,
+				corev1.LocalObjectReference{Name: secretName})
 		}
 	}
 }
@@ -196,6 +498,13 @@ func WithJobBackoffLimit(limit int32) JobOption {
 	}
 }
 
+// WithJobSuspend suspends or resumes the Job
+func WithJobSuspend(suspend bool) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Suspend = &suspend
+	}
+}
+
 // WithJobTTLSecondsAfterFinished sets TTL for cleanup after completion
 func WithJobTTLSecondsAfterFinished(seconds int32) JobOption {
 	return func(job *kbatch.Job) {
@@ -203,13 +512,147 @@ func WithJobTTLSecondsAfterFinished(seconds int32) JobOption {
 	}
 }
 
+// WithJobActiveDeadlineSeconds bounds how long the Job may run
+func WithJobActiveDeadlineSeconds(seconds int64) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.ActiveDeadlineSeconds = &seconds
+	}
+}
+
+// WithJobPodFailurePolicy sets the rules deciding how failed pods affect the Job
+func WithJobPodFailurePolicy(policy *kbatch.PodFailurePolicy) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.PodFailurePolicy = policy.DeepCopy()
+	}
+}
+
+// WithJobParallelism sets how many pods the Job runs at once
+func WithJobParallelism(parallelism int32) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Parallelism = &parallelism
+	}
+}
+
+// WithJobCompletions sets how many pods must succeed for the Job to succeed
+func WithJobCompletions(completions int32) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Completions = &completions
+	}
+}
+
+// WithJobCompletionMode sets whether the pods of the Job are indexed
+func WithJobCompletionMode(mode kbatch.CompletionMode) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.CompletionMode = &mode
+	}
+}
+
+// WithJobSuccessPolicy sets the rules that let an Indexed Job succeed early
+func WithJobSuccessPolicy(policy *kbatch.SuccessPolicy) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.SuccessPolicy = policy.DeepCopy()
+	}
+}
+
+// WithJobBackoffLimitPerIndex sets the number of retries of each index of an Indexed Job
+func WithJobBackoffLimitPerIndex(limit int32) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.BackoffLimitPerIndex = &limit
+	}
+}
+
+// WithJobSubdomain places the pods under the headless Service of that name
+func WithJobSubdomain(subdomain string) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.Subdomain = subdomain
+	}
+}
+
+// WithJobServiceAccountName sets the ServiceAccount the pods run as
+func WithJobServiceAccountName(name string) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.ServiceAccountName = name
+	}
+}
+
+// WithJobNodeSelector restricts the pods to nodes carrying the given labels
+func WithJobNodeSelector(selector map[string]string) JobOption {
+	return func(job *kbatch.Job) {
+		if len(selector) > 0 {
+			job.Spec.Template.Spec.NodeSelector = selector
+		}
+	}
+}
+
+// WithJobAffinity sets the node and pod affinity rules of the pods
+func WithJobAffinity(affinity *corev1.Affinity) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.Affinity = affinity.DeepCopy()
+	}
+}
+
+// WithJobTolerations sets the taints the pods tolerate
+func WithJobTolerations(tolerations []corev1.Toleration) JobOption {
+	return func(job *kbatch.Job) {
+		if len(tolerations) > 0 {
+			job.Spec.Template.Spec.Tolerations = tolerations
+		}
+	}
+}
+
+// WithJobTopologySpreadConstraints sets how the pods spread across topology domains
+func WithJobTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint) JobOption {
+	return func(job *kbatch.Job) {
+		if len(constraints) > 0 {
+			job.Spec.Template.Spec.TopologySpreadConstraints = constraints
+		}
+	}
+}
+
+// WithJobPriorityClassName sets the PriorityClass of the pods
+func WithJobPriorityClassName(name string) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.PriorityClassName = name
+	}
+}
+
+// WithJobRuntimeClassName sets the RuntimeClass the pods run with
+func WithJobRuntimeClassName(name *string) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.RuntimeClassName = name
+	}
+}
+
+// WithJobPodSecurityContext sets the pod-level security context
+func WithJobPodSecurityContext(securityContext *corev1.PodSecurityContext) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.SecurityContext = securityContext.DeepCopy()
+	}
+}
+
+// WithJobSecurityContext sets the container security context
+func WithJobSecurityContext(securityContext *corev1.SecurityContext) JobOption {
+	return func(job *kbatch.Job) {
+		job.Spec.Template.Spec.Containers[0].SecurityContext = securityContext.DeepCopy()
+	}
+}
+
+// WithJobInitContainers sets the init and sidecar containers of the pods
+func WithJobInitContainers(containers []corev1.Container) JobOption {
+	return func(job *kbatch.Job) {
+		if len(containers) > 0 {
+			job.Spec.Template.Spec.InitContainers = containers
+		}
+	}
+}
+
 // === Service Options ===
 
 // WithServicePort sets the service port
 func WithServicePort(port int32) ServiceOption {
 	return func(service *corev1.Service) {
 		service.Spec.Ports = []corev1.ServicePort{
-			{Port: port},
+			{Port: port, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt32(port)},
 		}
 	}
 }
@@ -240,6 +683,15 @@ func WithServiceType(serviceType corev1.ServiceType) ServiceOption {
 	}
 }
 
+// WithServiceHeadless gives the service no cluster IP, so that its DNS name
+// resolves to the pods themselves, ready or not
+func WithServiceHeadless() ServiceOption {
+	return func(service *corev1.Service) {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+		service.Spec.PublishNotReadyAddresses = true
+	}
+}
+
 // === Deployment Options ===
 
 // WithDeploymentReplicas sets the number of replicas
@@ -272,6 +724,173 @@ func WithDeploymentCommand(command []string) DeploymentOption {
 	}
 }
 
+// WithDeploymentArgs sets the container arguments
+func WithDeploymentArgs(args []string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(args) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].Args = args
+		}
+	}
+}
+
+// WithDeploymentEnv sets the container environment variables
+func WithDeploymentEnv(env []corev1.EnvVar) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(env) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].Env = env
+		}
+	}
+}
+
+// WithDeploymentEnvFrom sets the sources the container environment is populated from
+func WithDeploymentEnvFrom(envFrom []corev1.EnvFromSource) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(envFrom) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].EnvFrom = envFrom
+		}
+	}
+}
+
+// WithDeploymentWorkingDir sets the container working directory
+func WithDeploymentWorkingDir(dir string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].WorkingDir = dir
+	}
+}
+
+// WithDeploymentPorts sets the container ports
+func WithDeploymentPorts(ports []corev1.ContainerPort) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(ports) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].Ports = ports
+		}
+	}
+}
+
+// WithDeploymentVolumes sets the pod volumes
+func WithDeploymentVolumes(volumes []corev1.Volume) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(volumes) > 0 {
+			deployment.Spec.Template.Spec.Volumes = volumes
+		}
+	}
+}
+
+// WithDeploymentVolumeMounts sets the container volume mounts
+func WithDeploymentVolumeMounts(mounts []corev1.VolumeMount) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(mounts) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].VolumeMounts = mounts
+		}
+	}
+}
+
+// WithDeploymentResources sets the container resource requests and limits
+func WithDeploymentResources(resources corev1.ResourceRequirements) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].Resources = resources
+	}
+}
+
+// WithDeploymentServiceAccountName sets the ServiceAccount the pods run as
+func WithDeploymentServiceAccountName(name string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.ServiceAccountName = name
+	}
+}
+
+// WithDeploymentNodeSelector restricts the pods to nodes carrying the given labels
+func WithDeploymentNodeSelector(selector map[string]string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(selector) > 0 {
+			deployment.Spec.Template.Spec.NodeSelector = selector
+		}
+	}
+}
+
+// WithDeploymentAffinity sets the node and pod affinity rules of the pods
+func WithDeploymentAffinity(affinity *corev1.Affinity) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Affinity = affinity.DeepCopy()
+	}
+}
+
+// WithDeploymentTolerations sets the taints the pods tolerate
+func WithDeploymentTolerations(tolerations []corev1.Toleration) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(tolerations) > 0 {
+			deployment.Spec.Template.Spec.Tolerations = tolerations
+		}
+	}
+}
+
+// WithDeploymentTopologySpreadConstraints sets how the pods spread across topology domains
+func WithDeploymentTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(constraints) > 0 {
+			deployment.Spec.Template.Spec.TopologySpreadConstraints = constraints
+		}
+	}
+}
+
+// WithDeploymentPriorityClassName sets the PriorityClass of the pods
+func WithDeploymentPriorityClassName(name string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.PriorityClassName = name
+	}
+}
+
+// WithDeploymentRuntimeClassName sets the RuntimeClass the pods run with
+func WithDeploymentRuntimeClassName(name *string) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.RuntimeClassName = name
+	}
+}
+
+// WithDeploymentPodSecurityContext sets the pod-level security context
+func WithDeploymentPodSecurityContext(securityContext *corev1.PodSecurityContext) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.SecurityContext = securityContext.DeepCopy()
+	}
+}
+
+// WithDeploymentSecurityContext sets the container security context
+func WithDeploymentSecurityContext(securityContext *corev1.SecurityContext) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].SecurityContext = securityContext.DeepCopy()
+	}
+}
+
+// WithDeploymentLivenessProbe sets the probe restarting an unhealthy container
+func WithDeploymentLivenessProbe(probe *corev1.Probe) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].LivenessProbe = probe.DeepCopy()
+	}
+}
+
+// WithDeploymentReadinessProbe sets the probe gating traffic to the container
+func WithDeploymentReadinessProbe(probe *corev1.Probe) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = probe.DeepCopy()
+	}
+}
+
+// WithDeploymentStartupProbe sets the probe holding off the others until the container started
+func WithDeploymentStartupProbe(probe *corev1.Probe) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		deployment.Spec.Template.Spec.Containers[0].StartupProbe = probe.DeepCopy()
+	}
+}
+
+// WithDeploymentInitContainers sets the init and sidecar containers of the pods
+func WithDeploymentInitContainers(containers []corev1.Container) DeploymentOption {
+	return func(deployment *appsv1.Deployment) {
+		if len(containers) > 0 {
+			deployment.Spec.Template.Spec.InitContainers = containers
+		}
+	}
+}
+
 // WithDeploymentSelector sets labels used for pod selection (MatchLabels) - these also get added to pod template
 func WithDeploymentSelector(labels map[string]string) DeploymentOption {
 	return func(deployment *appsv1.Deployment) {
@@ -307,25 +926,210 @@ func WithDeploymentLabels(labels map[string]string) DeploymentOption {
 	}
 }
 
-// WithDeploymentImagePullSecret adds a single image pull secret
-func WithDeploymentImagePullSecret(secretName string) DeploymentOption {
+// WithDeploymentImagePullSecrets adds image pull secrets
+func WithDeploymentImagePullSecrets(secretNames ...string) DeploymentOption {
 	return func(deployment *appsv1.Deployment) {
-		if secretName != "" {
-			deployment.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
-				{Name: secretName},
-			}
+		for _, secretName := range secretNames {
+			deployment.Spec.Template.Spec.ImagePullSecrets = append(deployment.Spec.Template.Spec.ImagePullSecrets,
+				corev1.LocalObjectReference{Name: secretName})
 		}
 	}
 }
 
-// WithDeploymentPort adds a single container port
+// WithDeploymentPort adds a container port unless the container already declares it
 func WithDeploymentPort(port int32, protocol corev1.Protocol) DeploymentOption {
 	return func(deployment *appsv1.Deployment) {
-		deployment.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
-			{
-				ContainerPort: port,
-				Protocol:      protocol,
+		container := &deployment.Spec.Template.Spec.Containers[0]
+		for _, existing := range container.Ports {
+			if existing.ContainerPort == port && (existing.Protocol == protocol || existing.Protocol == "") {
+				return
+			}
+		}
+		container.Ports = append(slices.Clone(container.Ports), corev1.ContainerPort{
+			ContainerPort: port,
+			Protocol:      protocol,
+		})
+	}
+}
+
+// === CronJob Options ===
+
+// WithCronJobLabels sets metadata labels on the CronJob
+func WithCronJobLabels(labels map[string]string) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		if cronJob.Labels == nil {
+			cronJob.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			cronJob.Labels[k] = v
+		}
+	}
+}
+
+// WithCronJobSchedule sets the cron schedule and the time zone it is interpreted in
+func WithCronJobSchedule(schedule string, timeZone *string) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.Schedule = schedule
+		cronJob.Spec.TimeZone = timeZone
+	}
+}
+
+// WithCronJobConcurrencyPolicy sets how overlapping runs are handled
+func WithCronJobConcurrencyPolicy(policy kbatch.ConcurrencyPolicy) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.ConcurrencyPolicy = policy
+	}
+}
+
+// WithCronJobStartingDeadlineSeconds sets how late a missed run may still start
+func WithCronJobStartingDeadlineSeconds(seconds int64) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.StartingDeadlineSeconds = &seconds
+	}
+}
+
+// WithCronJobHistoryLimits sets how many succeeded and failed Jobs are kept
+func WithCronJobHistoryLimits(successful, failed int32) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.SuccessfulJobsHistoryLimit = &successful
+		cronJob.Spec.FailedJobsHistoryLimit = &failed
+	}
+}
+
+// WithCronJobSuspend suspends or resumes the schedule
+func WithCronJobSuspend(suspend bool) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.Suspend = &suspend
+	}
+}
+
+// WithCronJobJobTemplate makes the CronJob start Jobs like the given one
+func WithCronJobJobTemplate(job *kbatch.Job) CronJobOption {
+	return func(cronJob *kbatch.CronJob) {
+		cronJob.Spec.JobTemplate = kbatch.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      job.Labels,
+				Annotations: job.Annotations,
 			},
+			Spec: *job.Spec.DeepCopy(),
 		}
 	}
 }
+
+// === PersistentVolumeClaim Options ===
+
+// WithPVCLabels sets metadata labels on the claim
+func WithPVCLabels(labels map[string]string) PVCOption {
+	return func(pvc *corev1.PersistentVolumeClaim) {
+		if pvc.Labels == nil {
+			pvc.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			pvc.Labels[k] = v
+		}
+	}
+}
+
+// WithPVCSize sets the requested storage capacity
+func WithPVCSize(size resource.Quantity) PVCOption {
+	return func(pvc *corev1.PersistentVolumeClaim) {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+	}
+}
+
+// WithPVCStorageClassName sets the StorageClass the claim is provisioned from
+func WithPVCStorageClassName(name *string) PVCOption {
+	return func(pvc *corev1.PersistentVolumeClaim) {
+		pvc.Spec.StorageClassName = name
+	}
+}
+
+// WithPVCAccessModes sets the ways the volume can be mounted
+func WithPVCAccessModes(modes []corev1.PersistentVolumeAccessMode) PVCOption {
+	return func(pvc *corev1.PersistentVolumeClaim) {
+		pvc.Spec.AccessModes = modes
+	}
+}
+
+// === ServiceAccount, Role and RoleBinding Options ===
+
+// WithServiceAccountLabels sets metadata labels on the service account
+func WithServiceAccountLabels(labels map[string]string) ServiceAccountOption {
+	return func(serviceAccount *corev1.ServiceAccount) {
+		if serviceAccount.Labels == nil {
+			serviceAccount.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			serviceAccount.Labels[k] = v
+		}
+	}
+}
+
+// WithRoleLabels sets metadata labels on the role
+func WithRoleLabels(labels map[string]string) RoleOption {
+	return func(role *rbacv1.Role) {
+		if role.Labels == nil {
+			role.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			role.Labels[k] = v
+		}
+	}
+}
+
+// WithRoleRules sets the permissions the role grants
+func WithRoleRules(rules []rbacv1.PolicyRule) RoleOption {
+	return func(role *rbacv1.Role) {
+		role.Rules = rules
+	}
+}
+
+// WithRoleBindingLabels sets metadata labels on the role binding
+func WithRoleBindingLabels(labels map[string]string) RoleBindingOption {
+	return func(roleBinding *rbacv1.RoleBinding) {
+		if roleBinding.Labels == nil {
+			roleBinding.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			roleBinding.Labels[k] = v
+		}
+	}
+}
+
+// WithRoleBindingRole binds the Role of the given name
+func WithRoleBindingRole(name string) RoleBindingOption {
+	return func(roleBinding *rbacv1.RoleBinding) {
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+	}
+}
+
+// WithRoleBindingServiceAccount grants the role to the service account of the given name
+func WithRoleBindingServiceAccount(name string) RoleBindingOption {
+	return func(roleBinding *rbacv1.RoleBinding) {
+		roleBinding.Subjects = []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      name,
+			Namespace: roleBinding.Namespace,
+		}}
+	}
+}
+
+// WithSecretLabels sets metadata labels on the secret
+func WithSecretLabels(labels map[string]string) SecretOption {
+	return func(secret *corev1.Secret) {
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			secret.Labels[k] = v
+		}
+	}
+}
+
+// WithSecretData sets the type and data of the secret
+func WithSecretData(secretType corev1.SecretType, data map[string][]byte) SecretOption {
+	return func(secret *corev1.Secret) {
+		secret.Type = secretType
+		secret.Data = data
+	}
+}
@@ -2,127 +2,406 @@ package eva
 
 import (
 	"context"
-
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	kbatch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func (r *EvaReconciler) reconcileResources(ctx context.Context, eva *v1alpha1.Eva, currentState *evaCurrentState, logger logr.Logger) (*v1alpha1.EvaStatus, error) {
+func (r *EvaReconciler) reconcileResources(ctx context.Context, eva *v1alpha1.Eva, currentState *evaCurrentState, logger logr.Logger) (*v1alpha1.EvaStatus, ctrl.Result, error) {
 	statusUpdate := &v1alpha1.EvaStatus{}
-	statusUpdate, err := r.reconcileJob(ctx, eva, currentState.Job, logger)
+	result := ctrl.Result{}
+	if err := r.reconcileStorage(ctx, eva, currentState.Storage, logger); err != nil {
+		return nil, result, err
+	}
+	permissions, err := r.reconcileServiceAccount(ctx, eva, currentState.ServiceAccount, logger)
 	if err != nil {
-		return nil, err
+		return nil, result, err
+	}
+	pullSecrets, err := r.reconcilePullSecrets(ctx, eva, currentState.PullSecrets, logger)
+	if err != nil {
+		return nil, result, err
+	}
+	switch eva.Spec.Mode {
+	case v1alpha1.EvaModeDeployment:
+		if err = r.reconcileService(ctx, eva, currentState.Service, logger); err != nil {
+			return nil, result, err
+		}
+		statusUpdate, err = r.reconcileDeployment(ctx, eva, currentState.Deployment, logger)
+	case v1alpha1.EvaModeCronJob:
+		if err = r.reconcileHeadlessService(ctx, eva, currentState.Service, logger); err != nil {
+			return nil, result, err
+		}
+		statusUpdate, err = r.reconcileCronJob(ctx, eva, currentState.CronJob, logger)
+	default:
+		if err = r.reconcileHeadlessService(ctx, eva, currentState.Service, logger); err != nil {
+			return nil, result, err
+		}
+		statusUpdate, result, err = r.reconcileJob(ctx, eva, currentState.Job, logger)
+	}
+	if err != nil {
+		return nil, result, err
+	}
+	if !eva.Spec.Paused && statusUpdate.Phase != v1alpha1.EvaPhasePaused &&
+		meta.IsStatusConditionTrue(eva.Status.Conditions, string(v1alpha1.EvaConditionPaused)) {
+		statusUpdate.Conditions = append(statusUpdate.Conditions, metav1.Condition{
+			Type:               string(v1alpha1.EvaConditionPaused),
+			Status:             metav1.ConditionFalse,
+			Reason:             "Resumed",
+			Message:            "The Eva has been resumed.",
+			ObservedGeneration: eva.Generation,
+		})
+	}
+	podSecurity, err := r.podSecurityCondition(eva, currentState.Namespace)
+	if err != nil {
+		return nil, result, err
+	}
+	statusUpdate.Conditions = append(statusUpdate.Conditions, podSecurity)
+	if permissions != nil {
+		statusUpdate.Conditions = append(statusUpdate.Conditions, *permissions)
+	}
+	if pullSecrets != nil {
+		statusUpdate.Conditions = append(statusUpdate.Conditions, *pullSecrets)
+	}
+	if len(currentState.PullSecrets.Missing) > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > pullSecretPollInterval) {
+		result.RequeueAfter = pullSecretPollInterval
 	}
-	return statusUpdate, nil
+	return statusUpdate, result, nil
 }
 
-func (r *EvaReconciler) reconcileJob(ctx context.Context, eva *v1alpha1.Eva, jobState jobState, logger logr.Logger) (*v1alpha1.EvaStatus, error) {
+func (r *EvaReconciler) reconcileJob(ctx context.Context, eva *v1alpha1.Eva, jobState jobState, logger logr.Logger) (*v1alpha1.EvaStatus, ctrl.Result, error) {
 	newStatus := &v1alpha1.EvaStatus{}
+	desiredHash, err := r.desiredJobHash(eva)
+	if err != nil {
+		return nil, ctrl.Result{}, err
+	}
 	if !jobState.Exists {
-		if eva.Status.Phase == "" || eva.Status.Phase == v1alpha1.EvaPhasePending {
-			logger.Info("Creating Job for Eva", "Eva.Name", eva.Name)
-			if err := r.createJob(ctx, eva, logger); err != nil {
-				return nil, err
+		specChanged := eva.Status.SpecHash != "" && eva.Status.SpecHash != desiredHash &&
+			eva.Spec.UpdateStrategy != v1alpha1.EvaUpdateStrategyIgnore
+		switch {
+		case eva.Status.Phase == "" || eva.Status.Phase == v1alpha1.EvaPhasePending ||
+			eva.Status.Phase == v1alpha1.EvaPhasePaused || specChanged:
+			// A new spec starts over from the first attempt
+			attempt := currentAttempt(eva)
+			if specChanged || eva.Status.Phase == "" {
+				attempt = 1
 			}
-			newStatus.Phase = v1alpha1.EvaPhasePending
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionFalse,
-					Reason:             "JobCreated",
-					Message:            "The Job has been created.",
-					ObservedGeneration: eva.Generation,
-				},
+			desired, err := r.desiredJob(eva, attempt)
+			if err != nil {
+				return nil, ctrl.Result{}, err
 			}
-			return newStatus, nil
-		} else {
-			newStatus.Phase = v1alpha1.EvaPhaseFailed
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionFalse,
-					Reason:             "JobMissing",
-					Message:            "The Job is missing.",
-					ObservedGeneration: eva.Generation,
-				},
+			logger.Info("Creating Job for Eva", "Eva.Name", eva.Name, "paused", eva.Spec.Paused, "attempt", attempt)
+			if err := r.createJob(ctx, eva, desired, logger); err != nil {
+				var resolutionErr *imageResolutionError
+				if !errors.As(err, &resolutionErr) {
+					return nil, ctrl.Result{}, err
+				}
+				newStatus.Phase = v1alpha1.EvaPhasePending
+				newStatus.Conditions = evaConditions(eva,
+					metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse,
+					"ImageResolutionFailed", fmt.Sprintf("Resolving the digest of %s failed: %s", eva.Spec.Image, err))
+				return newStatus, ctrl.Result{RequeueAfter: imageResolutionRetryInterval}, nil
 			}
-			return newStatus, nil
-		}
-	} else {
-		if jobState.Succeeded > 0 {
-			newStatus.Phase = v1alpha1.EvaPhaseSucceeded
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionTrue,
-					Reason:             "JobSucceeded",
-					Message:            "The Job has succeeded.",
-					ObservedGeneration: eva.Generation,
-				},
+			newStatus.SpecHash = desiredHash
+			newStatus.ResolvedImage = desired.Annotations[resolvedImageAnnotation]
+			newStatus.JobGeneration = eva.Generation
+			newStatus.CurrentAttempt = attempt
+			newStatus.AttemptHistory = eva.Status.AttemptHistory
+			if eva.Spec.Paused {
+				newStatus.Phase = v1alpha1.EvaPhasePaused
+				newStatus.Conditions = pausedConditions(eva, "The Job has been created suspended.")
+				return newStatus, ctrl.Result{}, nil
 			}
-			return newStatus, nil
+			newStatus.Phase = v1alpha1.EvaPhasePending
+			newStatus.Conditions = evaConditions(eva,
+				metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+				"JobCreated", "The Job has been created.")
+			return newStatus, ctrl.Result{}, nil
+		case eva.Status.Phase == v1alpha1.EvaPhaseSucceeded || eva.Status.Phase == v1alpha1.EvaPhaseFailed:
+			// The Job of a finished run may be cleaned up after its TTL
+			newStatus.Phase = eva.Status.Phase
+			return newStatus, ctrl.Result{}, nil
+		default:
+			return r.handleFailedAttempt(ctx, eva, jobState, newStatus,
+				attemptFailure{Reason: "JobMissing", Message: "The Job is missing."}, logger)
 		}
-		if jobState.FailedPods > 0 {
-			newStatus.Phase = v1alpha1.EvaPhaseFailed
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionFalse,
-					Reason:             "JobFailed",
-					Message:            "The Job has failed.",
-					ObservedGeneration: eva.Generation,
-				},
-			}
-			return newStatus, nil
+	}
+
+	if jobState.Terminating {
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"JobTerminating", "Waiting for the previous Job to be deleted.")
+		return newStatus, ctrl.Result{}, nil
+	}
+	if r.shouldReplaceJob(eva, jobState, desiredHash) {
+		logger.Info("Replacing Job for Eva", "Eva.Name", eva.Name, "updateStrategy", eva.Spec.UpdateStrategy,
+			"currentSpecHash", jobState.SpecHash, "desiredSpecHash", desiredHash)
+		if err := r.deleteJob(ctx, eva, logger); err != nil {
+			return nil, ctrl.Result{}, err
 		}
-		if jobState.ImagePullFailed {
-			newStatus.Phase = v1alpha1.EvaPhaseFailed
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionFalse,
-					Reason:             "ImagePullBackOff",
-					Message:            "Failed to pull container image.",
-					ObservedGeneration: eva.Generation,
-				},
-			}
-			return newStatus, nil
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"JobReplaced", "The Job is being replaced to apply the updated spec.")
+		return newStatus, ctrl.Result{}, nil
+	}
+	newStatus.SpecHash = jobState.SpecHash
+	newStatus.JobGeneration = jobState.Generation
+	newStatus.ResolvedImage = jobState.ResolvedImage
+	newStatus.CurrentAttempt = jobState.Attempt
+	newStatus.AttemptHistory = eva.Status.AttemptHistory
+	setRunStatus(newStatus, jobState)
+	if !jobState.Finished && jobState.Suspended != eva.Spec.Paused {
+		if err := r.setJobSuspended(ctx, eva, eva.Spec.Paused, logger); err != nil {
+			return nil, ctrl.Result{}, err
 		}
-		newStatus.Phase = v1alpha1.EvaPhaseRunning
-		// Only add condition if it would actually change
-		existingCondition := meta.FindStatusCondition(eva.Status.Conditions, string(v1alpha1.EvaConditionAvailable))
-		if existingCondition == nil || existingCondition.Status != metav1.ConditionTrue ||
-			existingCondition.Reason != "JobRunning" {
-			newStatus.Conditions = []metav1.Condition{
-				{
-					Type:               string(v1alpha1.EvaConditionAvailable),
-					Status:             metav1.ConditionTrue,
-					Reason:             "JobRunning",
-					Message:            "The Job is running.",
-					ObservedGeneration: eva.Generation,
-				},
-			}
+	}
+	if !jobState.Finished && eva.Spec.Parallelism != nil && jobState.Parallelism != *eva.Spec.Parallelism {
+		if err := r.setJobParallelism(ctx, eva, *eva.Spec.Parallelism, logger); err != nil {
+			return nil, ctrl.Result{}, err
 		}
 	}
-	return newStatus, nil
+	if !jobState.Finished && eva.Spec.Paused {
+		newStatus.Phase = v1alpha1.EvaPhasePaused
+		newStatus.Conditions = pausedConditions(eva, "The Job is suspended.")
+		return newStatus, ctrl.Result{}, nil
+	}
+	if jobState.Complete {
+		newStatus.AttemptHistory = recordAttempt(eva.Status.AttemptHistory, v1alpha1.EvaAttemptStatus{
+			Attempt:    jobState.Attempt,
+			JobName:    jobState.Ref.Name,
+			StartTime:  jobState.StartTime,
+			FinishTime: jobState.CompletionTime,
+			Reason:     "JobSucceeded",
+			Message:    "The Job has succeeded.",
+		})
+		newStatus.Phase = v1alpha1.EvaPhaseSucceeded
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse,
+			"JobSucceeded", "The Job has succeeded.")
+		return newStatus, ctrl.Result{}, nil
+	}
+	if jobState.Failed {
+		return r.handleFailedAttempt(ctx, eva, jobState, newStatus, jobFailure(jobState), logger)
+	}
+	if failure := jobState.PodFailure; failure.Reason != "" {
+		if remaining := imagePullGraceRemaining(eva, failure); remaining > 0 {
+			newStatus.Phase = v1alpha1.EvaPhasePending
+			newStatus.Conditions = evaConditions(eva,
+				metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse,
+				failure.Reason, fmt.Sprintf("%s (failing after the %s image pull grace period)",
+					failure.Message, eva.Spec.FailurePolicy.ImagePullGracePeriod.Duration))
+			return newStatus, ctrl.Result{RequeueAfter: remaining}, nil
+		}
+		switch {
+		case failure.Fatal:
+			return r.handleFailedAttempt(ctx, eva, jobState, newStatus,
+				attemptFailure{Reason: failure.Reason, Message: failure.Message}, logger)
+		case failure.Started:
+			newStatus.Phase = v1alpha1.EvaPhaseRunning
+			newStatus.Conditions = evaConditions(eva,
+				metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse,
+				failure.Reason, failure.Message)
+		default:
+			newStatus.Phase = v1alpha1.EvaPhasePending
+			newStatus.Conditions = evaConditions(eva,
+				metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse,
+				failure.Reason, failure.Message)
+		}
+		return newStatus, ctrl.Result{}, nil
+	}
+	message := "The Job is running."
+	if jobState.Completions > 1 {
+		message = fmt.Sprintf("The Job is running (%s).", newStatus.Progress)
+	}
+	newStatus.Phase = v1alpha1.EvaPhaseRunning
+	newStatus.Conditions = evaConditions(eva,
+		metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+		"JobRunning", message)
+	return newStatus, ctrl.Result{}, nil
+}
+
+// imagePullGraceRemaining returns how much longer an image pull failure is
+// tolerated before the Eva is failed, or zero when it no longer is
+func imagePullGraceRemaining(eva *v1alpha1.Eva, failure podFailure) time.Duration {
+	if failure.Reason != podFailureImagePullBackOff || eva.Spec.FailurePolicy == nil ||
+		eva.Spec.FailurePolicy.ImagePullGracePeriod == nil {
+		return 0
+	}
+	gracePeriod := eva.Spec.FailurePolicy.ImagePullGracePeriod.Duration
+	// The kubelet sets the start time before pulling, so this only guards
+	// against a status that has not caught up yet
+	if failure.Since.IsZero() {
+		return gracePeriod
+	}
+	return time.Until(failure.Since.Add(gracePeriod))
 }
 
-func (r *EvaReconciler) createJob(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
-	jobName := fmt.Sprintf("%s-job", eva.Name)
+// setRunStatus records the timing, children and container outcomes of the current Job
+func setRunStatus(status *v1alpha1.EvaStatus, jobState jobState) {
+	jobRef := jobState.Ref
+	status.JobRef = &jobRef
+	status.StartTime = jobState.StartTime
+	status.CompletionTime = jobState.CompletionTime
+	status.PodRefs = jobState.PodRefs
+	status.Attempts = jobState.Attempts
+	status.Containers = jobState.Containers
+	status.CompletedIndexes = jobState.CompletedIndexes
+	status.FailedIndexes = jobState.FailedIndexes
+	if jobState.Completions > 0 {
+		status.Progress = fmt.Sprintf("%d/%d completed", jobState.Succeeded, jobState.Completions)
+	}
+}
+
+// shouldReplaceJob reports whether the current Job was built from an outdated
+// spec and the update strategy allows replacing it now
+func (r *EvaReconciler) shouldReplaceJob(eva *v1alpha1.Eva, jobState jobState, desiredHash string) bool {
+	// Jobs created before spec hashing was introduced are adopted as-is
+	if jobState.SpecHash == "" || jobState.SpecHash == desiredHash {
+		return false
+	}
+	switch eva.Spec.UpdateStrategy {
+	case v1alpha1.EvaUpdateStrategyIgnore:
+		return false
+	case v1alpha1.EvaUpdateStrategyReplace:
+		return true
+	default:
+		return jobState.Finished || jobState.ImagePullFailed
+	}
+}
+
+func (r *EvaReconciler) desiredJob(eva *v1alpha1.Eva, attempt int32) (*kbatch.Job, error) {
 	containerName := fmt.Sprintf("%s-container", eva.Name)
-	desired := buildJob(jobName, eva.Namespace,
+	backoffLimit := int32(0)
+	if eva.Spec.BackoffLimit != nil {
+		backoffLimit = *eva.Spec.BackoffLimit
+	}
+	volumes, mounts := podVolumes(eva)
+	opts := []JobOption{
 		WithJobLabels(r.generateLabels(eva, nil)),
 		WithJobContainerName(containerName),
 		WithJobImage(eva.Spec.Image),
 		WithJobCommand(eva.Spec.Command),
-		WithJobImagePullSecret(eva.Spec.ImagePullSecret),
-		WithJobBackoffLimit(0))
+		WithJobArgs(eva.Spec.Args),
+		WithJobEnv(eva.Spec.Env),
+		WithJobEnvFrom(eva.Spec.EnvFrom),
+		WithJobWorkingDir(eva.Spec.WorkingDir),
+		WithJobPorts(eva.Spec.Ports),
+		WithJobVolumes(volumes),
+		WithJobVolumeMounts(mounts),
+		WithJobImagePullSecrets(imagePullSecretNames(eva)...),
+		WithJobResources(eva.Spec.Resources),
+		WithJobBackoffLimit(backoffLimit),
+		WithJobNodeSelector(eva.Spec.Scheduling.NodeSelector),
+		WithJobAffinity(eva.Spec.Scheduling.Affinity),
+		WithJobTolerations(eva.Spec.Scheduling.Tolerations),
+		WithJobTopologySpreadConstraints(r.topologySpreadConstraints(eva)),
+		WithJobPriorityClassName(eva.Spec.Scheduling.PriorityClassName),
+		WithJobRuntimeClassName(eva.Spec.Scheduling.RuntimeClassName),
+	}
+	if name := serviceAccountName(eva); name != "" {
+		opts = append(opts, WithJobServiceAccountName(name))
+	}
+	initContainers := podInitContainers(eva)
+	podOverrides, containerOverrides := securityOverrides(eva)
+	opts = append(opts,
+		WithJobInitContainers(initContainers),
+		WithJobPodSecurityContext(podOverrides),
+		WithJobSecurityContext(containerOverrides))
+	if eva.Spec.ActiveDeadlineSeconds != nil {
+		opts = append(opts, WithJobActiveDeadlineSeconds(*eva.Spec.ActiveDeadlineSeconds))
+	}
+	if eva.Spec.PodFailurePolicy != nil {
+		opts = append(opts, WithJobPodFailurePolicy(eva.Spec.PodFailurePolicy))
+	}
+	if eva.Spec.Completions != nil {
+		opts = append(opts, WithJobCompletions(*eva.Spec.Completions))
+	}
+	if eva.Spec.CompletionMode == kbatch.IndexedCompletion {
+		opts = append(opts, WithJobCompletionMode(kbatch.IndexedCompletion), WithJobSubdomain(headlessServiceName(eva)))
+	}
+	if eva.Spec.SuccessPolicy != nil {
+		opts = append(opts, WithJobSuccessPolicy(eva.Spec.SuccessPolicy))
+	}
+	if eva.Spec.BackoffLimitPerIndex != nil {
+		opts = append(opts, WithJobBackoffLimitPerIndex(*eva.Spec.BackoffLimitPerIndex))
+		// Without a backoffLimit of its own, only the indexes bound the retries
+		if eva.Spec.BackoffLimit == nil {
+			opts = append(opts, WithJobBackoffLimit(math.MaxInt32))
+		}
+	}
+	desired := buildJob(jobNameForAttempt(eva, attempt), eva.Namespace, opts...)
+
+	specHash, err := computeSpecHash(desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+	// Suspension and parallelism are changed in place and the TTL only matters
+	// once the run is over, so none of them counts as a spec change
+	WithJobSuspend(eva.Spec.Paused)(desired)
+	if eva.Spec.Parallelism != nil {
+		WithJobParallelism(*eva.Spec.Parallelism)(desired)
+	}
+	if eva.Spec.TTLSecondsAfterFinished != nil {
+		WithJobTTLSecondsAfterFinished(*eva.Spec.TTLSecondsAfterFinished)(desired)
+	}
+	// Only the Eva's own overrides are hashed, so that opting an Eva in to the
+	// restricted defaults does not replace the Job it already ran
+	podSecurity, containerSecurity := securityContexts(eva)
+	WithJobPodSecurityContext(podSecurity)(desired)
+	WithJobSecurityContext(containerSecurity)(desired)
+	WithJobInitContainers(restrictedInitContainers(eva, initContainers))(desired)
+	if volume, mount := tmpVolume(containerSecurity, mounts); volume != nil {
+		WithJobVolumes(append(volumes, *volume))(desired)
+		WithJobVolumeMounts(append(mounts, *mount))(desired)
+	}
+	WithJobAnnotations(map[string]string{
+		specHashAnnotation:      specHash,
+		evaGenerationAnnotation: strconv.FormatInt(eva.Generation, 10),
+		attemptAnnotation:       strconv.FormatInt(int64(attempt), 10),
+	})(desired)
+	return desired, nil
+}
+
+// desiredJobHash returns the spec hash of the Job the Eva currently asks for
+func (r *EvaReconciler) desiredJobHash(eva *v1alpha1.Eva) (string, error) {
+	desired, err := r.desiredJob(eva, 1)
+	if err != nil {
+		return "", err
+	}
+	return desired.Annotations[specHashAnnotation], nil
+}
 
+// createJob creates the Job, running it by digest when the Eva asks for it.
+// The digest is applied after hashing, so that moving the tag does not count
+// as a spec change.
+func (r *EvaReconciler) createJob(ctx context.Context, eva *v1alpha1.Eva, desired *kbatch.Job, logger logr.Logger) error {
+	if eva.Spec.ResolveImageDigest {
+		image, err := r.pinnedImage(ctx, eva)
+		if err != nil {
+			logger.Error(err, "failed to resolve image digest: ", "error", err, "image", eva.Spec.Image)
+			return &imageResolutionError{err: err}
+		}
+		WithJobImage(image)(desired)
+		WithJobAnnotations(map[string]string{resolvedImageAnnotation: image})(desired)
+	}
 	if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
 		logger.Error(err, "failed to set controller reference: ", "error", err)
 		return err
@@ -132,10 +411,428 @@ func (r *EvaReconciler) createJob(ctx context.Context, eva *v1alpha1.Eva, logger
 		return err
 	}
 
-	logger.Info("Created Job for Eva", "eva", eva.Name, "image", eva.Spec.Image)
+	logger.Info("Created Job for Eva", "eva", eva.Name, "image", desired.Spec.Template.Spec.Containers[0].Image)
+	return nil
+}
+
+func (r *EvaReconciler) setJobSuspended(ctx context.Context, eva *v1alpha1.Eva, suspend bool, logger logr.Logger) error {
+	job, err := GetOwnedJob(ctx, r.Client, eva, ownerKey)
+	if err != nil || job == nil {
+		return err
+	}
+	patch := client.MergeFrom(job.DeepCopy())
+	WithJobSuspend(suspend)(job)
+	if err := r.Patch(ctx, job, patch); err != nil {
+		logger.Error(err, "failed to update job suspension: ", "error", err)
+		return err
+	}
+
+	logger.Info("Updated Job suspension for Eva", "eva", eva.Name, "job", job.Name, "suspend", suspend)
+	return nil
+}
+
+// setJobParallelism scales the running Job to the given number of pods
+func (r *EvaReconciler) setJobParallelism(ctx context.Context, eva *v1alpha1.Eva, parallelism int32, logger logr.Logger) error {
+	job, err := GetOwnedJob(ctx, r.Client, eva, ownerKey)
+	if err != nil || job == nil {
+		return err
+	}
+	patch := client.MergeFrom(job.DeepCopy())
+	WithJobParallelism(parallelism)(job)
+	if err := r.Patch(ctx, job, patch); err != nil {
+		logger.Error(err, "failed to update job parallelism: ", "error", err)
+		return err
+	}
+
+	logger.Info("Updated Job parallelism for Eva", "eva", eva.Name, "job", job.Name, "parallelism", parallelism)
+	return nil
+}
+
+func (r *EvaReconciler) deleteJob(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	job, err := GetOwnedJob(ctx, r.Client, eva, ownerKey)
+	if err != nil || job == nil {
+		return err
+	}
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "failed to delete job: ", "error", err)
+		return err
+	}
+
+	logger.Info("Deleted Job for Eva", "eva", eva.Name, "job", job.Name)
 	return nil
 }
 
+func (r *EvaReconciler) reconcileDeployment(ctx context.Context, eva *v1alpha1.Eva, deploymentState deploymentState, logger logr.Logger) (*v1alpha1.EvaStatus, error) {
+	newStatus := &v1alpha1.EvaStatus{}
+	if !deploymentState.Exists {
+		logger.Info("Creating Deployment for Eva", "Eva.Name", eva.Name)
+		if err := r.createDeployment(ctx, eva, logger); err != nil {
+			return nil, err
+		}
+		if eva.Spec.Paused {
+			newStatus.Phase = v1alpha1.EvaPhasePaused
+			newStatus.Conditions = pausedConditions(eva, "The Deployment has been created scaled to zero.")
+			return newStatus, nil
+		}
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"DeploymentCreated", "The Deployment has been created.")
+		return newStatus, nil
+	}
+
+	if err := r.updateDeploymentIfChanged(ctx, eva, logger); err != nil {
+		return nil, err
+	}
+	if eva.Spec.Paused {
+		newStatus.Phase = v1alpha1.EvaPhasePaused
+		newStatus.Conditions = pausedConditions(eva, "The Deployment is scaled to zero.")
+		return newStatus, nil
+	}
+
+	rollout := fmt.Sprintf("(%d/%d replicas available, %d ready).",
+		deploymentState.AvailableReplicas, deploymentState.Replicas, deploymentState.ReadyReplicas)
+	switch {
+	case deploymentState.DeadlineExceeded:
+		newStatus.Phase = v1alpha1.EvaPhaseFailed
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue,
+			"ProgressDeadlineExceeded", "The Deployment rollout exceeded its progress deadline "+rollout)
+	case deploymentState.Ready:
+		newStatus.Phase = v1alpha1.EvaPhaseRunning
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse,
+			"DeploymentAvailable", "The Deployment is available "+rollout)
+	case deploymentState.RolledOut:
+		newStatus.Phase = v1alpha1.EvaPhaseRunning
+		newStatus.Conditions = evaConditions(eva,
+			availableStatus(deploymentState), metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse,
+			"ReplicasUnavailable", "The Deployment is missing replicas "+rollout)
+	default:
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		if deploymentState.AvailableReplicas > 0 {
+			newStatus.Phase = v1alpha1.EvaPhaseRunning
+		}
+		newStatus.Conditions = evaConditions(eva,
+			availableStatus(deploymentState), metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+			"RollingOut", "The Deployment is rolling out "+rollout)
+	}
+	return newStatus, nil
+}
+
+// evaConditions builds the Available, Progressing, Degraded and Failed
+// conditions reported for an Eva, sharing one reason and message, along with
+// the Ready condition summarising them for kstatus-aware tooling
+func evaConditions(eva *v1alpha1.Eva, available, progressing, degraded, failed metav1.ConditionStatus, reason, message string) []metav1.Condition {
+	conditions := []metav1.Condition{}
+	conditionTypes := []v1alpha1.EvaConditionType{v1alpha1.EvaConditionAvailable, v1alpha1.EvaConditionProgressing, v1alpha1.EvaConditionDegraded, v1alpha1.EvaConditionFailed}
+	for i, status := range []metav1.ConditionStatus{available, progressing, degraded, failed} {
+		conditions = append(conditions, metav1.Condition{
+			Type:               string(conditionTypes[i]),
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: eva.Generation,
+		})
+	}
+	ready := metav1.ConditionFalse
+	if available == metav1.ConditionTrue && degraded != metav1.ConditionTrue && failed != metav1.ConditionTrue {
+		ready = metav1.ConditionTrue
+	}
+	return append(conditions, metav1.Condition{
+		Type:               string(v1alpha1.EvaConditionReady),
+		Status:             ready,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: eva.Generation,
+	})
+}
+
+// pausedConditions reports a paused Eva as unavailable and not progressing
+func pausedConditions(eva *v1alpha1.Eva, message string) []metav1.Condition {
+	conditions := evaConditions(eva,
+		metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse, "Paused", message)
+	return append(conditions, metav1.Condition{
+		Type:               string(v1alpha1.EvaConditionPaused),
+		Status:             metav1.ConditionTrue,
+		Reason:             "Paused",
+		Message:            message,
+		ObservedGeneration: eva.Generation,
+	})
+}
+
+func availableStatus(deploymentState deploymentState) metav1.ConditionStatus {
+	if deploymentState.AvailableReplicas > 0 {
+		return metav1.ConditionTrue
+	}
+	return metav1.ConditionFalse
+}
+
+// reconcileService fronts the Deployment with a Service on spec.port, and
+// removes it once the port is cleared
+func (r *EvaReconciler) reconcileService(ctx context.Context, eva *v1alpha1.Eva, serviceState serviceState, logger logr.Logger) error {
+	if eva.Spec.Port == 0 {
+		if !serviceState.Exists {
+			return nil
+		}
+		existing, err := GetOwnedService(ctx, r.Client, eva, ownerKey)
+		if err != nil || existing == nil {
+			return err
+		}
+		if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete service: ", "error", err)
+			return err
+		}
+		logger.Info("Deleted Service for Eva", "eva", eva.Name, "service", existing.Name)
+		return nil
+	}
+	desired := r.desiredService(eva)
+	if !serviceState.Exists {
+		if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
+			logger.Error(err, "failed to set controller reference: ", "error", err)
+			return err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			logger.Error(err, "failed to create service: ", "error", err)
+			return err
+		}
+		logger.Info("Created Service for Eva", "eva", eva.Name, "port", eva.Spec.Port)
+		return nil
+	}
+
+	existing, err := GetOwnedService(ctx, r.Client, eva, ownerKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(desired.Spec.Ports, existing.Spec.Ports) {
+		return nil
+	}
+	existing.Spec.Ports = desired.Spec.Ports
+	logger.Info("Updating Service for Eva", "eva", eva.Name, "port", eva.Spec.Port)
+	return r.Update(ctx, existing)
+}
+
+func (r *EvaReconciler) createDeployment(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	desired := r.desiredDeployment(eva)
+	if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
+		logger.Error(err, "failed to set controller reference: ", "error", err)
+		return err
+	}
+	if err := r.Create(ctx, desired); err != nil {
+		logger.Error(err, "failed to create deployment: ", "error", err)
+		return err
+	}
+
+	logger.Info("Created Deployment for Eva", "eva", eva.Name, "image", eva.Spec.Image)
+	return nil
+}
+
+// updateDeploymentIfChanged rolls the Deployment when the Eva spec no longer matches its pod template or replicas
+func (r *EvaReconciler) updateDeploymentIfChanged(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	existing, err := GetOwnedDeployment(ctx, r.Client, eva, ownerKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	desired := r.desiredDeployment(eva)
+	if equality.Semantic.DeepEqual(desired.Spec.Replicas, existing.Spec.Replicas) &&
+		equality.Semantic.DeepEqual(ownedPodTemplate(&desired.Spec.Template), ownedPodTemplate(&existing.Spec.Template)) {
+		return nil
+	}
+
+	existing.Spec.Replicas = desired.Spec.Replicas
+	existing.Spec.Template = desired.Spec.Template
+	logger.Info("Updating Deployment for Eva", "eva", eva.Name, "image", eva.Spec.Image)
+	return r.Update(ctx, existing)
+}
+
+// ownedPodTemplate keeps the parts of a pod template the controller sets, with
+// the values the API server defaults inside them filled in, so that templates
+// compare equal unless the Eva changed, fields it dropped included
+func ownedPodTemplate(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	spec := &template.Spec
+	owned := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: template.Labels},
+		Spec: corev1.PodSpec{
+			Volumes:                   slices.Clone(spec.Volumes),
+			ImagePullSecrets:          spec.ImagePullSecrets,
+			NodeSelector:              spec.NodeSelector,
+			Affinity:                  spec.Affinity,
+			Tolerations:               spec.Tolerations,
+			TopologySpreadConstraints: spec.TopologySpreadConstraints,
+			PriorityClassName:         spec.PriorityClassName,
+			RuntimeClassName:          spec.RuntimeClassName,
+			ServiceAccountName:        spec.ServiceAccountName,
+			SecurityContext:           spec.SecurityContext,
+			Subdomain:                 spec.Subdomain,
+			RestartPolicy:             spec.RestartPolicy,
+		},
+	}
+	if owned.Spec.RestartPolicy == "" {
+		owned.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	}
+	// An empty pod security context is what the API server stores for none
+	if equality.Semantic.DeepEqual(owned.Spec.SecurityContext, &corev1.PodSecurityContext{}) {
+		owned.Spec.SecurityContext = nil
+	}
+	for i := range owned.Spec.Volumes {
+		defaultVolume(&owned.Spec.Volumes[i])
+	}
+	for _, container := range spec.InitContainers {
+		owned.Spec.InitContainers = append(owned.Spec.InitContainers, ownedContainer(container))
+	}
+	for _, container := range spec.Containers {
+		owned.Spec.Containers = append(owned.Spec.Containers, ownedContainer(container))
+	}
+	return owned
+}
+
+func ownedContainer(container corev1.Container) corev1.Container {
+	owned := corev1.Container{
+		Name:            container.Name,
+		Image:           container.Image,
+		Command:         container.Command,
+		Args:            container.Args,
+		WorkingDir:      container.WorkingDir,
+		Ports:           slices.Clone(container.Ports),
+		EnvFrom:         container.EnvFrom,
+		Env:             slices.Clone(container.Env),
+		Resources:       *container.Resources.DeepCopy(),
+		VolumeMounts:    container.VolumeMounts,
+		LivenessProbe:   defaultProbe(container.LivenessProbe),
+		ReadinessProbe:  defaultProbe(container.ReadinessProbe),
+		StartupProbe:    defaultProbe(container.StartupProbe),
+		RestartPolicy:   container.RestartPolicy,
+		SecurityContext: container.SecurityContext,
+	}
+	for i := range owned.Ports {
+		if owned.Ports[i].Protocol == "" {
+			owned.Ports[i].Protocol = corev1.ProtocolTCP
+		}
+	}
+	for i, env := range owned.Env {
+		if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.APIVersion == "" {
+			owned.Env[i].ValueFrom = env.ValueFrom.DeepCopy()
+			owned.Env[i].ValueFrom.FieldRef.APIVersion = "v1"
+		}
+	}
+	// Requests default to the limits they are missing for
+	for name, limit := range owned.Resources.Limits {
+		if _, ok := owned.Resources.Requests[name]; !ok {
+			if owned.Resources.Requests == nil {
+				owned.Resources.Requests = corev1.ResourceList{}
+			}
+			owned.Resources.Requests[name] = limit
+		}
+	}
+	return owned
+}
+
+func defaultProbe(probe *corev1.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	probe = probe.DeepCopy()
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == "" {
+		probe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	return probe
+}
+
+func defaultVolume(volume *corev1.Volume) {
+	volume.VolumeSource = *volume.VolumeSource.DeepCopy()
+	switch source := &volume.VolumeSource; {
+	case source.ConfigMap != nil && source.ConfigMap.DefaultMode == nil:
+		source.ConfigMap.DefaultMode = ptr.To(corev1.ConfigMapVolumeSourceDefaultMode)
+	case source.Secret != nil && source.Secret.DefaultMode == nil:
+		source.Secret.DefaultMode = ptr.To(corev1.SecretVolumeSourceDefaultMode)
+	case source.Projected != nil && source.Projected.DefaultMode == nil:
+		source.Projected.DefaultMode = ptr.To(corev1.ProjectedVolumeSourceDefaultMode)
+	case source.DownwardAPI != nil && source.DownwardAPI.DefaultMode == nil:
+		source.DownwardAPI.DefaultMode = ptr.To(corev1.DownwardAPIVolumeSourceDefaultMode)
+	case source.HostPath != nil && source.HostPath.Type == nil:
+		source.HostPath.Type = ptr.To(corev1.HostPathUnset)
+	}
+}
+
+func (r *EvaReconciler) desiredDeployment(eva *v1alpha1.Eva) *appsv1.Deployment {
+	deploymentName := fmt.Sprintf("%s-deployment", eva.Name)
+	containerName := fmt.Sprintf("%s-container", eva.Name)
+	replicas := int32(1)
+	if eva.Spec.Replicas != nil {
+		replicas = *eva.Spec.Replicas
+	}
+	if eva.Spec.Paused {
+		replicas = 0
+	}
+	volumes, mounts := podVolumes(eva)
+	opts := []DeploymentOption{
+		WithDeploymentSelector(r.generateLabels(eva, nil)),
+		WithDeploymentLabels(r.generateLabels(eva, nil)),
+		WithDeploymentReplicas(replicas),
+		WithDeploymentContainerName(containerName),
+		WithDeploymentImage(eva.Spec.Image),
+		WithDeploymentCommand(eva.Spec.Command),
+		WithDeploymentArgs(eva.Spec.Args),
+		WithDeploymentEnv(eva.Spec.Env),
+		WithDeploymentEnvFrom(eva.Spec.EnvFrom),
+		WithDeploymentWorkingDir(eva.Spec.WorkingDir),
+		WithDeploymentPorts(eva.Spec.Ports),
+		WithDeploymentVolumes(volumes),
+		WithDeploymentVolumeMounts(mounts),
+		WithDeploymentImagePullSecrets(imagePullSecretNames(eva)...),
+		WithDeploymentResources(eva.Spec.Resources),
+		WithDeploymentNodeSelector(eva.Spec.Scheduling.NodeSelector),
+		WithDeploymentAffinity(eva.Spec.Scheduling.Affinity),
+		WithDeploymentTolerations(eva.Spec.Scheduling.Tolerations),
+		WithDeploymentTopologySpreadConstraints(r.topologySpreadConstraints(eva)),
+		WithDeploymentPriorityClassName(eva.Spec.Scheduling.PriorityClassName),
+		WithDeploymentRuntimeClassName(eva.Spec.Scheduling.RuntimeClassName),
+		WithDeploymentServiceAccountName(serviceAccountName(eva)),
+	}
+	podSecurity, containerSecurity := securityContexts(eva)
+	opts = append(opts,
+		WithDeploymentInitContainers(restrictedInitContainers(eva, podInitContainers(eva))),
+		WithDeploymentPodSecurityContext(podSecurity),
+		WithDeploymentSecurityContext(containerSecurity))
+	if volume, mount := tmpVolume(containerSecurity, mounts); volume != nil {
+		opts = append(opts, WithDeploymentVolumes(append(volumes, *volume)), WithDeploymentVolumeMounts(append(mounts, *mount)))
+	}
+	if eva.Spec.Port > 0 {
+		opts = append(opts, WithDeploymentPort(eva.Spec.Port, corev1.ProtocolTCP))
+	}
+	if eva.Spec.Probes != nil {
+		opts = append(opts,
+			WithDeploymentLivenessProbe(eva.Spec.Probes.Liveness),
+			WithDeploymentReadinessProbe(eva.Spec.Probes.Readiness),
+			WithDeploymentStartupProbe(eva.Spec.Probes.Startup))
+	}
+	return buildDeployment(deploymentName, eva.Namespace, opts...)
+}
+
+func (r *EvaReconciler) desiredService(eva *v1alpha1.Eva) *corev1.Service {
+	serviceName := fmt.Sprintf("%s-service", eva.Name)
+	return buildService(serviceName, eva.Namespace,
+		WithServiceLabels(r.generateLabels(eva, nil)),
+		WithServiceSelector(r.generateLabels(eva, nil)),
+		WithServiceType(corev1.ServiceTypeClusterIP),
+		WithServicePort(eva.Spec.Port))
+}
+
 func (r *EvaReconciler) generateLabels(eva *v1alpha1.Eva, newLabels map[string]string) map[string]string {
 	labels := map[string]string{
 		"app":      "eva-controller",
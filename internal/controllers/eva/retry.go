@@ -0,0 +1,218 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const defaultRetryBackoff = 10 * time.Second
+const defaultMaxRetryBackoff = 10 * time.Minute
+
+// maxAttemptHistory bounds the number of attempts kept in the Eva status
+const maxAttemptHistory = 10
+
+// attemptFailure describes why the current attempt failed
+type attemptFailure struct {
+	Reason   string
+	Message  string
+	ExitCode *int32
+}
+
+// jobNameForAttempt names the Job of an attempt. The first attempt keeps the
+// name used before retries existed.
+func jobNameForAttempt(eva *v1alpha1.Eva, attempt int32) string {
+	if attempt <= 1 {
+		return fmt.Sprintf("%s-job", eva.Name)
+	}
+	return fmt.Sprintf("%s-job-%d", eva.Name, attempt)
+}
+
+// currentAttempt returns the attempt recorded in the Eva status
+func currentAttempt(eva *v1alpha1.Eva) int32 {
+	return max(eva.Status.CurrentAttempt, 1)
+}
+
+// jobFailure explains a failed Job, preferring the most specific reason
+// found on its pods over the one on the Job itself
+func jobFailure(jobState jobState) attemptFailure {
+	failure := attemptFailure{Reason: "JobFailed", Message: "The Job has failed."}
+	if jobState.FailedReason != "" {
+		failure.Reason = jobState.FailedReason
+		failure.Message = fmt.Sprintf("The Job has failed: %s", jobState.FailedMessage)
+	}
+	if container := failedContainer(jobState.Containers); container != nil {
+		exitCode := *container.ExitCode
+		failure.ExitCode = &exitCode
+		if container.Reason != "" {
+			failure.Reason = container.Reason
+		}
+		failure.Message = fmt.Sprintf("The Job has failed: container %s exited with code %d. %s",
+			container.Name, exitCode, container.Message)
+	}
+	if jobState.PodFailure.Reason != "" {
+		failure.Reason = jobState.PodFailure.Reason
+		failure.Message = fmt.Sprintf("The Job has failed: %s", jobState.PodFailure.Message)
+	}
+	return failure
+}
+
+// failedContainer returns the container whose nonzero exit code explains the
+// failure: the main container, or an init or sidecar container when the main
+// container never ran, as sidecars are killed once the main container exits
+func failedContainer(containers []v1alpha1.EvaContainerStatus) *v1alpha1.EvaContainerStatus {
+	failed := func(container v1alpha1.EvaContainerStatus) bool {
+		return container.ExitCode != nil && *container.ExitCode != 0
+	}
+	mainRan := false
+	for i, container := range containers {
+		if container.Type != v1alpha1.EvaContainerTypeMain {
+			continue
+		}
+		if failed(container) {
+			return &containers[i]
+		}
+		mainRan = mainRan || container.ExitCode != nil || container.State == "Running" || container.State == "Terminated"
+	}
+	if mainRan {
+		return nil
+	}
+	if i := slices.IndexFunc(containers, failed); i >= 0 {
+		return &containers[i]
+	}
+	return nil
+}
+
+// retryAllowed reports whether the retry policy allows another attempt after this failure
+func retryAllowed(policy *v1alpha1.EvaRetryPolicy, attempt int32, failure attemptFailure) bool {
+	if policy == nil || attempt >= max(policy.MaxAttempts, 1) {
+		return false
+	}
+	if len(policy.RetryOnReasons) > 0 && !slices.Contains(policy.RetryOnReasons, failure.Reason) {
+		return false
+	}
+	if failure.ExitCode != nil && slices.Contains(policy.NoRetryOnExitCodes, *failure.ExitCode) {
+		return false
+	}
+	return true
+}
+
+// retryBackoff returns the delay after the given failed attempt, doubling
+// from the base backoff up to the maximum
+func retryBackoff(policy *v1alpha1.EvaRetryPolicy, attempt int32) time.Duration {
+	backoff, maxBackoff := defaultRetryBackoff, defaultMaxRetryBackoff
+	if policy.Backoff != nil {
+		backoff = policy.Backoff.Duration
+	}
+	if policy.MaxBackoff != nil {
+		maxBackoff = policy.MaxBackoff.Duration
+	}
+	for i := int32(1); i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// recordAttempt appends a finished attempt to the history unless it is
+// already its latest entry, keeping the most recent maxAttemptHistory entries
+func recordAttempt(history []v1alpha1.EvaAttemptStatus, record v1alpha1.EvaAttemptStatus) []v1alpha1.EvaAttemptStatus {
+	if n := len(history); n > 0 {
+		last := history[n-1]
+		if last.Attempt == record.Attempt && last.JobName == record.JobName &&
+			last.StartTime.Equal(record.StartTime) {
+			return history
+		}
+	}
+	history = append(slices.Clone(history), record)
+	if len(history) > maxAttemptHistory {
+		history = history[len(history)-maxAttemptHistory:]
+	}
+	return history
+}
+
+// lastFinishTime returns when the attempt was recorded as finished, if it was
+func lastFinishTime(history []v1alpha1.EvaAttemptStatus, attempt int32, jobName string) *metav1.Time {
+	if n := len(history); n > 0 && history[n-1].Attempt == attempt && history[n-1].JobName == jobName {
+		return history[n-1].FinishTime
+	}
+	return nil
+}
+
+// handleFailedAttempt records the failed attempt and, as the retry policy
+// allows, waits out the backoff and starts the next attempt or fails the Eva
+func (r *EvaReconciler) handleFailedAttempt(ctx context.Context, eva *v1alpha1.Eva, jobState jobState, newStatus *v1alpha1.EvaStatus, failure attemptFailure, logger logr.Logger) (*v1alpha1.EvaStatus, ctrl.Result, error) {
+	attempt := currentAttempt(eva)
+	jobName := jobNameForAttempt(eva, attempt)
+	if jobState.Exists {
+		attempt, jobName = jobState.Attempt, jobState.Ref.Name
+	}
+	finishTime := lastFinishTime(eva.Status.AttemptHistory, attempt, jobName)
+	if finishTime == nil {
+		finishTime = jobState.CompletionTime
+	}
+	if finishTime == nil {
+		now := metav1.Now()
+		finishTime = &now
+	}
+	newStatus.CurrentAttempt = attempt
+	newStatus.AttemptHistory = recordAttempt(eva.Status.AttemptHistory, v1alpha1.EvaAttemptStatus{
+		Attempt:    attempt,
+		JobName:    jobName,
+		StartTime:  jobState.StartTime,
+		FinishTime: finishTime,
+		Reason:     failure.Reason,
+		Message:    failure.Message,
+		ExitCode:   failure.ExitCode,
+	})
+
+	policy := eva.Spec.RetryPolicy
+	if !retryAllowed(policy, attempt, failure) {
+		newStatus.Phase = v1alpha1.EvaPhaseFailed
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue,
+			failure.Reason, failure.Message)
+		return newStatus, ctrl.Result{}, nil
+	}
+
+	maxAttempts := max(policy.MaxAttempts, 1)
+	if wait := time.Until(finishTime.Add(retryBackoff(policy, attempt))); wait > 0 {
+		newStatus.Phase = v1alpha1.EvaPhasePending
+		newStatus.Conditions = evaConditions(eva,
+			metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionFalse,
+			"RetryBackoff", fmt.Sprintf("Attempt %d of %d failed with %s, retrying in %s: %s",
+				attempt, maxAttempts, failure.Reason, wait.Round(time.Second), failure.Message))
+		return newStatus, ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	logger.Info("Retrying Eva", "Eva.Name", eva.Name, "failedAttempt", attempt, "reason", failure.Reason)
+	if jobState.Exists {
+		if err := r.deleteJob(ctx, eva, logger); err != nil {
+			return nil, ctrl.Result{}, err
+		}
+	}
+	next := attempt + 1
+	desired, err := r.desiredJob(eva, next)
+	if err != nil {
+		return nil, ctrl.Result{}, err
+	}
+	// The Job may already exist when the cache has not yet seen it
+	if err := r.createJob(ctx, eva, desired, logger); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, ctrl.Result{}, err
+	}
+	newStatus.CurrentAttempt = next
+	newStatus.SpecHash = desired.Annotations[specHashAnnotation]
+	newStatus.ResolvedImage = desired.Annotations[resolvedImageAnnotation]
+	newStatus.JobGeneration = eva.Generation
+	newStatus.Phase = v1alpha1.EvaPhasePending
+	newStatus.Conditions = evaConditions(eva,
+		metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse,
+		"RetryStarted", fmt.Sprintf("Started attempt %d of %d after %s.", next, maxAttempts, failure.Reason))
+	return newStatus, ctrl.Result{}, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eva
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+)
+
+var _ = Describe("Eva retry policy", func() {
+	policy := &geofrontv1alpha1.EvaRetryPolicy{
+		MaxAttempts:        3,
+		Backoff:            &metav1.Duration{Duration: 10 * time.Second},
+		MaxBackoff:         &metav1.Duration{Duration: 30 * time.Second},
+		RetryOnReasons:     []string{"ImagePullBackOff", "Error"},
+		NoRetryOnExitCodes: []int32{2},
+	}
+
+	DescribeTable("deciding whether to retry",
+		func(attempt int32, failure attemptFailure, expected bool) {
+			Expect(retryAllowed(policy, attempt, failure)).To(Equal(expected))
+		},
+		Entry("a listed reason", int32(1), attemptFailure{Reason: "ImagePullBackOff"}, true),
+		Entry("an unlisted reason", int32(1), attemptFailure{Reason: "OOMKilled"}, false),
+		Entry("a listed reason with a final exit code", int32(1), attemptFailure{Reason: "Error", ExitCode: ptr.To(int32(2))}, false),
+		Entry("a listed reason with another exit code", int32(2), attemptFailure{Reason: "Error", ExitCode: ptr.To(int32(1))}, true),
+		Entry("the last attempt", int32(3), attemptFailure{Reason: "ImagePullBackOff"}, false),
+	)
+
+	It("should take the exit code of the main container over a killed sidecar", func() {
+		failure := jobFailure(jobState{Containers: []geofrontv1alpha1.EvaContainerStatus{
+			{Name: "log-shipper", Type: geofrontv1alpha1.EvaContainerTypeSidecar, State: "Terminated", ExitCode: ptr.To(int32(143))},
+			{Name: "unit-01-container", Type: geofrontv1alpha1.EvaContainerTypeMain, State: "Terminated", ExitCode: ptr.To(int32(2)), Reason: "Error"},
+		}})
+		Expect(failure.ExitCode).To(HaveValue(Equal(int32(2))))
+		Expect(failure.Reason).To(Equal("Error"))
+		Expect(failure.Message).To(ContainSubstring("container unit-01-container exited with code 2"))
+		Expect(retryAllowed(policy, 1, failure)).To(BeFalse())
+	})
+
+	It("should take the exit code of an init container when the main container never ran", func() {
+		failure := jobFailure(jobState{Containers: []geofrontv1alpha1.EvaContainerStatus{
+			{Name: "migrate", Type: geofrontv1alpha1.EvaContainerTypeInit, State: "Terminated", ExitCode: ptr.To(int32(1)), Reason: "Error"},
+			{Name: "unit-01-container", Type: geofrontv1alpha1.EvaContainerTypeMain, State: "Waiting"},
+		}})
+		Expect(failure.ExitCode).To(HaveValue(Equal(int32(1))))
+		Expect(failure.Message).To(ContainSubstring("container migrate exited with code 1"))
+	})
+
+	It("should never retry without a policy", func() {
+		Expect(retryAllowed(nil, 1, attemptFailure{Reason: "JobFailed"})).To(BeFalse())
+	})
+
+	It("should double the backoff up to the maximum", func() {
+		Expect(retryBackoff(policy, 1)).To(Equal(10 * time.Second))
+		Expect(retryBackoff(policy, 2)).To(Equal(20 * time.Second))
+		Expect(retryBackoff(policy, 3)).To(Equal(30 * time.Second))
+		Expect(retryBackoff(&geofrontv1alpha1.EvaRetryPolicy{}, 2)).To(Equal(2 * defaultRetryBackoff))
+	})
+
+	It("should keep the latest attempts once and in order", func() {
+		var history []geofrontv1alpha1.EvaAttemptStatus
+		for attempt := int32(1); attempt <= maxAttemptHistory+2; attempt++ {
+			record := geofrontv1alpha1.EvaAttemptStatus{Attempt: attempt, JobName: "unit-01-job"}
+			history = recordAttempt(history, record)
+			history = recordAttempt(history, record)
+		}
+		Expect(history).To(HaveLen(maxAttemptHistory))
+		Expect(history[0].Attempt).To(Equal(int32(3)))
+		Expect(history[maxAttemptHistory-1].Attempt).To(Equal(int32(maxAttemptHistory + 2)))
+	})
+})
@@ -0,0 +1,21 @@
+package eva
+
+import (
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// topologySpreadConstraints returns the Eva's spread constraints, selecting
+// the Eva's own pods where a constraint has no label selector
+func (r *EvaReconciler) topologySpreadConstraints(eva *v1alpha1.Eva) []corev1.TopologySpreadConstraint {
+	constraints := make([]corev1.TopologySpreadConstraint, 0, len(eva.Spec.Scheduling.TopologySpreadConstraints))
+	for _, constraint := range eva.Spec.Scheduling.TopologySpreadConstraints {
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: r.generateLabels(eva, nil)}
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
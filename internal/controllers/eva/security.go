@@ -0,0 +1,263 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podSecurityEnforceLabel carries the Pod Security level a namespace enforces
+const podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+const (
+	podSecurityLevelBaseline   = "baseline"
+	podSecurityLevelRestricted = "restricted"
+)
+
+const tmpMountPath = "/tmp"
+
+// restrictedDefaults reports whether the Eva's pods get the restricted
+// defaults, which every workload kind follows alike
+func restrictedDefaults(eva *v1alpha1.Eva) bool {
+	return eva.Annotations[v1alpha1.EvaRestrictedDefaultsAnnotation] == "true"
+}
+
+// baselineCapabilities are the capabilities the baseline level allows containers to add
+var baselineCapabilities = []corev1.Capability{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// safeSysctls are the sysctls the baseline level allows pods to set
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+}
+
+// securityContexts returns the pod and container security contexts of the
+// Eva's pods: its overrides, with the restricted defaults filling the rest
+// when the Eva gets them. The root filesystem is only read-only where the Eva
+// says its image allows it, as nothing else tells.
+func securityContexts(eva *v1alpha1.Eva) (*corev1.PodSecurityContext, *corev1.SecurityContext) {
+	if !restrictedDefaults(eva) {
+		return securityOverrides(eva)
+	}
+	pod := &corev1.PodSecurityContext{}
+	container := &corev1.SecurityContext{}
+	if eva.Spec.SecurityContext != nil {
+		if eva.Spec.SecurityContext.Pod != nil {
+			pod = eva.Spec.SecurityContext.Pod.DeepCopy()
+		}
+		if eva.Spec.SecurityContext.Container != nil {
+			container = eva.Spec.SecurityContext.Container.DeepCopy()
+		}
+	}
+	if pod.RunAsNonRoot == nil {
+		pod.RunAsNonRoot = ptr.To(true)
+	}
+	if pod.SeccompProfile == nil {
+		pod.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	restrictContainer(container)
+	return pod, container
+}
+
+// restrictContainer fills in the container settings the restricted level
+// requires where the security context leaves them unset
+func restrictContainer(securityContext *corev1.SecurityContext) {
+	if securityContext.AllowPrivilegeEscalation == nil {
+		securityContext.AllowPrivilegeEscalation = ptr.To(false)
+	}
+	if securityContext.Capabilities == nil {
+		securityContext.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+	}
+}
+
+// restrictedInitContainers returns the init and sidecar containers with the
+// restricted defaults filled in when the Eva gets them
+func restrictedInitContainers(eva *v1alpha1.Eva, containers []corev1.Container) []corev1.Container {
+	if !restrictedDefaults(eva) {
+		return containers
+	}
+	restricted := make([]corev1.Container, 0, len(containers))
+	for _, container := range containers {
+		container = *container.DeepCopy()
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		restrictContainer(container.SecurityContext)
+		restricted = append(restricted, container)
+	}
+	return restricted
+}
+
+// securityOverrides returns the security contexts exactly as the Eva sets them
+func securityOverrides(eva *v1alpha1.Eva) (*corev1.PodSecurityContext, *corev1.SecurityContext) {
+	if eva.Spec.SecurityContext == nil {
+		return nil, nil
+	}
+	return eva.Spec.SecurityContext.Pod, eva.Spec.SecurityContext.Container
+}
+
+// tmpVolume returns a writable volume for /tmp when the root filesystem is
+// read-only and nothing else is mounted there
+func tmpVolume(container *corev1.SecurityContext, mounts []corev1.VolumeMount) (*corev1.Volume, *corev1.VolumeMount) {
+	if container == nil || container.ReadOnlyRootFilesystem == nil || !*container.ReadOnlyRootFilesystem {
+		return nil, nil
+	}
+	if slices.ContainsFunc(mounts, func(mount corev1.VolumeMount) bool { return mount.MountPath == tmpMountPath }) {
+		return nil, nil
+	}
+	return &corev1.Volume{
+		Name:         v1alpha1.EvaTmpVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}, &corev1.VolumeMount{
+		Name:      v1alpha1.EvaTmpVolumeName,
+		MountPath: tmpMountPath,
+	}
+}
+
+// desiredPodSpec returns the pod spec the Eva's workload runs its pods with
+func (r *EvaReconciler) desiredPodSpec(eva *v1alpha1.Eva) (*corev1.PodSpec, error) {
+	if eva.Spec.Mode == v1alpha1.EvaModeDeployment {
+		return &r.desiredDeployment(eva).Spec.Template.Spec, nil
+	}
+	job, err := r.desiredJob(eva, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &job.Spec.Template.Spec, nil
+}
+
+// podSecurityCondition reports whether the Pod Security level enforced by the
+// Eva's namespace admits the pods of its workload
+func (r *EvaReconciler) podSecurityCondition(eva *v1alpha1.Eva, namespaceState namespaceState) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:               string(v1alpha1.EvaConditionPodSecurityAdmitted),
+		Status:             metav1.ConditionTrue,
+		Reason:             "NotEnforced",
+		Message:            "The namespace does not enforce a restricting Pod Security level.",
+		ObservedGeneration: eva.Generation,
+	}
+	level := namespaceState.PodSecurityLevel
+	if level != podSecurityLevelBaseline && level != podSecurityLevelRestricted {
+		return condition, nil
+	}
+	podSpec, err := r.desiredPodSpec(eva)
+	if err != nil {
+		return condition, err
+	}
+	if violations := podSecurityViolations(level, podSpec); len(violations) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "PodSecurityViolation"
+		condition.Message = fmt.Sprintf("The namespace enforces the %q Pod Security level, which would reject the pod: %s.",
+			level, strings.Join(violations, "; "))
+		return condition, nil
+	}
+	condition.Reason = "Admitted"
+	condition.Message = fmt.Sprintf("The pod satisfies the %q Pod Security level the namespace enforces.", level)
+	return condition, nil
+}
+
+// podSecurityViolations lists the checks of the baseline or restricted Pod
+// Security level the pod fails. Volume types are not checked since admission
+// only lets Evas use volumes both levels allow.
+func podSecurityViolations(level string, podSpec *corev1.PodSpec) []string {
+	var violations []string
+	pod := podSpec.SecurityContext
+	if pod == nil {
+		pod = &corev1.PodSecurityContext{}
+	}
+	if podSpec.HostNetwork || podSpec.HostPID || podSpec.HostIPC {
+		violations = append(violations, "pod must not share host namespaces")
+	}
+	for _, sysctl := range pod.Sysctls {
+		if !slices.Contains(safeSysctls, sysctl.Name) {
+			violations = append(violations, fmt.Sprintf("pod must not set the unsafe sysctl %s", sysctl.Name))
+		}
+	}
+	if pod.SeccompProfile != nil && pod.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		violations = append(violations, "pod must not set an Unconfined seccomp profile")
+	}
+
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		name := fmt.Sprintf("container %q", container.Name)
+		if sc.Privileged != nil && *sc.Privileged {
+			violations = append(violations, name+" must not be privileged")
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !slices.Contains(baselineCapabilities, capability) ||
+					(level == podSecurityLevelRestricted && capability != "NET_BIND_SERVICE") {
+					violations = append(violations, fmt.Sprintf("%s must not add the capability %s", name, capability))
+				}
+			}
+		}
+		if slices.ContainsFunc(container.Ports, func(port corev1.ContainerPort) bool { return port.HostPort != 0 }) {
+			violations = append(violations, name+" must not use host ports")
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			violations = append(violations, name+" must not set an Unconfined seccomp profile")
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			violations = append(violations, name+" must use the default proc mount")
+		}
+		if level != podSecurityLevelRestricted {
+			continue
+		}
+
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			violations = append(violations, name+" must set allowPrivilegeEscalation to false")
+		}
+		runAsNonRoot := sc.RunAsNonRoot
+		if runAsNonRoot == nil {
+			runAsNonRoot = pod.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			violations = append(violations, name+" must run as non-root")
+		}
+		runAsUser := sc.RunAsUser
+		if runAsUser == nil {
+			runAsUser = pod.RunAsUser
+		}
+		if runAsUser != nil && *runAsUser == 0 {
+			violations = append(violations, name+" must not run as user 0")
+		}
+		seccompProfile := sc.SeccompProfile
+		if seccompProfile == nil {
+			seccompProfile = pod.SeccompProfile
+		}
+		if seccompProfile == nil || (seccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault &&
+			seccompProfile.Type != corev1.SeccompProfileTypeLocalhost) {
+			violations = append(violations, name+" must use the RuntimeDefault or a Localhost seccomp profile")
+		}
+		if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+			violations = append(violations, name+" must drop all capabilities")
+		}
+	}
+	return violations
+}
+
+// getNamespaceState observes the Pod Security level the Eva's namespace enforces
+func (r *EvaReconciler) getNamespaceState(ctx context.Context, eva *v1alpha1.Eva) (namespaceState, error) {
+	namespaceState := namespaceState{}
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: eva.Namespace}, namespace); err != nil {
+		return namespaceState, client.IgnoreNotFound(err)
+	}
+	namespaceState.PodSecurityLevel = namespace.Labels[podSecurityEnforceLabel]
+	return namespaceState, nil
+}
@@ -0,0 +1,214 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// serviceAccountName returns the ServiceAccount the Eva's pods run as, empty
+// for the namespace's default one
+func serviceAccountName(eva *v1alpha1.Eva) string {
+	switch {
+	case eva.Spec.ServiceAccount == nil:
+		return ""
+	case eva.Spec.ServiceAccount.Create:
+		return fmt.Sprintf("%s-serviceaccount", eva.Name)
+	default:
+		return eva.Spec.ServiceAccount.Name
+	}
+}
+
+func roleName(eva *v1alpha1.Eva) string {
+	return fmt.Sprintf("%s-role", eva.Name)
+}
+
+func (r *EvaReconciler) desiredServiceAccount(eva *v1alpha1.Eva) *corev1.ServiceAccount {
+	return buildServiceAccount(serviceAccountName(eva), eva.Namespace,
+		WithServiceAccountLabels(r.generateLabels(eva, nil)))
+}
+
+func (r *EvaReconciler) desiredRole(eva *v1alpha1.Eva) *rbacv1.Role {
+	return buildRole(roleName(eva), eva.Namespace,
+		WithRoleLabels(r.generateLabels(eva, nil)),
+		WithRoleRules(eva.Spec.Permissions))
+}
+
+func (r *EvaReconciler) desiredRoleBinding(eva *v1alpha1.Eva) *rbacv1.RoleBinding {
+	return buildRoleBinding(fmt.Sprintf("%s-rolebinding", eva.Name), eva.Namespace,
+		WithRoleBindingLabels(r.generateLabels(eva, nil)),
+		WithRoleBindingRole(roleName(eva)),
+		WithRoleBindingServiceAccount(serviceAccountName(eva)))
+}
+
+// getServiceAccountState observes the ServiceAccount, Role and RoleBinding owned by this Eva
+func (r *EvaReconciler) getServiceAccountState(ctx context.Context, eva *v1alpha1.Eva) (serviceAccountState, error) {
+	serviceAccountState := serviceAccountState{}
+	serviceAccount, err := GetOwnedServiceAccount(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return serviceAccountState, err
+	}
+	role, err := GetOwnedRole(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return serviceAccountState, err
+	}
+	roleBinding, err := GetOwnedRoleBinding(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return serviceAccountState, err
+	}
+	serviceAccountState.Exists = serviceAccount != nil
+	serviceAccountState.RoleExists = role != nil
+	serviceAccountState.RoleBindingExists = roleBinding != nil
+	return serviceAccountState, nil
+}
+
+// reconcileServiceAccount creates the ServiceAccount of an Eva that asks for
+// one, bound to a Role granting its permissions, and removes them once the Eva
+// no longer does. The controller holds no escalate or bind verbs, so the API
+// server only stops it granting more than it holds itself, Secrets in every
+// namespace included. The validating webhook is what checks that the Eva's
+// author holds the permissions, so none are granted unless it reviews them; a
+// refusal is reported on the PermissionsGranted condition.
+func (r *EvaReconciler) reconcileServiceAccount(ctx context.Context, eva *v1alpha1.Eva, serviceAccountState serviceAccountState, logger logr.Logger) (*metav1.Condition, error) {
+	if eva.Spec.ServiceAccount == nil || !eva.Spec.ServiceAccount.Create {
+		if !serviceAccountState.Exists && !serviceAccountState.RoleExists && !serviceAccountState.RoleBindingExists {
+			return nil, nil
+		}
+		return nil, r.deletePermissions(ctx, eva, true, logger)
+	}
+
+	condition := &metav1.Condition{
+		Type:               string(v1alpha1.EvaConditionPermissionsGranted),
+		Status:             metav1.ConditionTrue,
+		Reason:             "Granted",
+		Message:            fmt.Sprintf("The pods run as the ServiceAccount %s.", serviceAccountName(eva)),
+		ObservedGeneration: eva.Generation,
+	}
+	if !serviceAccountState.Exists {
+		if err := r.createOwned(ctx, eva, r.desiredServiceAccount(eva), logger); err != nil {
+			return nil, err
+		}
+	}
+	if len(eva.Spec.Permissions) == 0 || !r.PermissionsReviewed {
+		if !r.PermissionsReviewed && len(eva.Spec.Permissions) > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "NotReviewed"
+			condition.Message = "The permissions are not granted, as no admission webhook checks that the Eva's author holds them."
+		}
+		if serviceAccountState.RoleExists || serviceAccountState.RoleBindingExists {
+			return condition, r.deletePermissions(ctx, eva, false, logger)
+		}
+		return condition, nil
+	}
+
+	err := r.applyRole(ctx, eva, serviceAccountState, logger)
+	if err == nil && !serviceAccountState.RoleBindingExists {
+		err = r.createOwned(ctx, eva, r.desiredRoleBinding(eva), logger)
+	}
+	if apierrors.IsForbidden(err) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Forbidden"
+		condition.Message = fmt.Sprintf("The controller may not grant the requested permissions: %s", err)
+		return condition, nil
+	}
+	return condition, err
+}
+
+// applyRole creates the Eva's Role, or updates its rules to the Eva's permissions
+func (r *EvaReconciler) applyRole(ctx context.Context, eva *v1alpha1.Eva, serviceAccountState serviceAccountState, logger logr.Logger) error {
+	if !serviceAccountState.RoleExists {
+		return r.createOwned(ctx, eva, r.desiredRole(eva), logger)
+	}
+	existing, err := GetOwnedRole(ctx, r.Client, eva, ownerKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Rules, eva.Spec.Permissions) {
+		return nil
+	}
+	patch := client.MergeFrom(existing.DeepCopy())
+	WithRoleRules(eva.Spec.Permissions)(existing)
+	logger.Info("Updating Role for Eva", "eva", eva.Name, "role", existing.Name)
+	return r.Patch(ctx, existing, patch)
+}
+
+// createOwned creates obj with the Eva as its controller
+func (r *EvaReconciler) createOwned(ctx context.Context, eva *v1alpha1.Eva, obj client.Object, logger logr.Logger) error {
+	if err := controllerutil.SetControllerReference(eva, obj, r.Scheme); err != nil {
+		logger.Error(err, "failed to set controller reference: ", "error", err)
+		return err
+	}
+	if err := r.Create(ctx, obj); err != nil {
+		logger.Error(err, "failed to create object: ", "error", err, "name", obj.GetName())
+		return err
+	}
+	logger.Info("Created object for Eva", "eva", eva.Name, "kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+	return nil
+}
+
+// ownedPermissions returns the RoleBinding, Role and, when asked, the
+// ServiceAccount the Eva owns
+func (r *EvaReconciler) ownedPermissions(ctx context.Context, eva *v1alpha1.Eva, withServiceAccount bool) ([]client.Object, error) {
+	var objs []client.Object
+	roleBinding, err := GetOwnedRoleBinding(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	if roleBinding != nil {
+		objs = append(objs, roleBinding)
+	}
+	role, err := GetOwnedRole(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	if role != nil {
+		objs = append(objs, role)
+	}
+	if !withServiceAccount {
+		return objs, nil
+	}
+	serviceAccount, err := GetOwnedServiceAccount(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	if serviceAccount != nil {
+		objs = append(objs, serviceAccount)
+	}
+	return objs, nil
+}
+
+// deletePermissions deletes the RoleBinding and Role of the Eva, and its
+// ServiceAccount when asked
+func (r *EvaReconciler) deletePermissions(ctx context.Context, eva *v1alpha1.Eva, withServiceAccount bool, logger logr.Logger) error {
+	objs, err := r.ownedPermissions(ctx, eva, withServiceAccount)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete object: ", "error", err, "name", obj.GetName())
+			return err
+		}
+		logger.Info("Deleted object of Eva", "eva", eva.Name, "kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+	}
+	return nil
+}
+
+// releasePermissions deletes the ServiceAccount, Role and RoleBinding of a
+// deleted Eva. The pods of an orphaned workload keep running as the
+// ServiceAccount, so orphanWorkload detaches them along with it instead.
+func (r *EvaReconciler) releasePermissions(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	if eva.Spec.DeletionPolicy == v1alpha1.EvaDeletionPolicyOrphan {
+		return nil
+	}
+	return r.deletePermissions(ctx, eva, true, logger)
+}
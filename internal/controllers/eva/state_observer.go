@@ -2,11 +2,18 @@ package eva
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 
 	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	kbatch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -19,6 +26,34 @@ func (r *EvaReconciler) getCurrentState(ctx context.Context, eva *v1alpha1.Eva,
 	if err != nil {
 		return currentState, err
 	}
+	currentState.Deployment, err = r.getDeploymentState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.Service, err = r.getServiceState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.CronJob, err = r.getCronJobState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.Storage, err = r.getStorageState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.Namespace, err = r.getNamespaceState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.ServiceAccount, err = r.getServiceAccountState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
+	currentState.PullSecrets, err = r.getPullSecretState(ctx, eva)
+	if err != nil {
+		return currentState, err
+	}
 	return currentState, nil
 }
 
@@ -40,45 +75,370 @@ func (r *EvaReconciler) getJobState(ctx context.Context, eva *v1alpha1.Eva, logg
 	jobState.Succeeded = job.Status.Succeeded
 	jobState.Active = job.Status.Active
 	jobState.FailedPods = job.Status.Failed
+	jobState.Terminating = !job.DeletionTimestamp.IsZero()
+	jobState.Suspended = job.Spec.Suspend != nil && *job.Spec.Suspend
+	jobState.SpecHash = job.Annotations[specHashAnnotation]
+	jobState.ResolvedImage = job.Annotations[resolvedImageAnnotation]
+	jobState.Generation, _ = strconv.ParseInt(job.Annotations[evaGenerationAnnotation], 10, 64)
+	jobState.Finished = isJobFinished(job)
+	jobState.Failed = isJobConditionTrue(job, kbatch.JobFailed)
+	jobState.Parallelism = ptr.Deref(job.Spec.Parallelism, 1)
+	// A Job without completions works a queue when it runs pods in parallel
+	if job.Spec.Completions != nil {
+		jobState.Completions = *job.Spec.Completions
+	} else if jobState.Parallelism <= 1 {
+		jobState.Completions = 1
+	}
+	jobState.CompletedIndexes = job.Status.CompletedIndexes
+	jobState.FailedIndexes = ptr.Deref(job.Status.FailedIndexes, "")
+	// Indexed Jobs count each succeeded index once, so succeeded is their progress too
+	jobState.Complete = isJobConditionTrue(job, kbatch.JobComplete) || isJobConditionTrue(job, kbatch.JobSuccessCriteriaMet) ||
+		(jobState.Completions > 0 && jobState.Succeeded >= jobState.Completions)
+	jobState.Attempt = 1
+	if attempt, err := strconv.ParseInt(job.Annotations[attemptAnnotation], 10, 32); err == nil && attempt > 0 {
+		jobState.Attempt = int32(attempt)
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == kbatch.JobFailed && condition.Status == corev1.ConditionTrue {
+			jobState.FailedReason = condition.Reason
+			jobState.FailedMessage = condition.Message
+		}
+	}
+	jobState.Ref = corev1.ObjectReference{
+		APIVersion: kbatch.SchemeGroupVersion.String(),
+		Kind:       "Job",
+		Namespace:  job.Namespace,
+		Name:       job.Name,
+		UID:        job.UID,
+	}
+	jobState.StartTime = job.Status.StartTime
+	jobState.CompletionTime = jobCompletionTime(job)
+	jobState.Attempts = job.Status.Active + job.Status.Succeeded + job.Status.Failed
 
-	// Check for image pull errors in Pods
-	jobState.ImagePullFailed, err = r.checkPodImagePullErrors(ctx, job, logger)
+	pods, err := r.listJobPods(ctx, job)
 	if err != nil {
-		logger.Error(err, "Failed to check pod image pull status")
+		logger.Error(err, "Failed to list pods of the Job")
+		return jobState, nil
+	}
+	for _, pod := range pods {
+		jobState.PodRefs = append(jobState.PodRefs, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  pod.Namespace,
+			Name:       pod.Name,
+			UID:        pod.UID,
+		})
 	}
+	if len(pods) > 0 {
+		jobState.Containers = containerStatuses(&pods[len(pods)-1])
+	}
+
+	jobState.PodFailure = detectPodFailure(pods, logger)
+	jobState.ImagePullFailed = jobState.PodFailure.Reason == podFailureImagePullBackOff ||
+		jobState.PodFailure.Reason == podFailureInvalidImageName
 
 	return jobState, nil
 }
 
-// checkPodImagePullErrors checks if any pods owned by the job have image pull errors
-func (r *EvaReconciler) checkPodImagePullErrors(ctx context.Context, job *kbatch.Job, logger logr.Logger) (bool, error) {
+// listJobPods lists the pods created by the Job, oldest first
+func (r *EvaReconciler) listJobPods(ctx context.Context, job *kbatch.Job) ([]corev1.Pod, error) {
 	podList := &corev1.PodList{}
 	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
-		return false, err
-	}
-
-	for _, pod := range podList.Items {
-		// Check container statuses for image pull errors
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			if containerStatus.State.Waiting != nil {
-				reason := containerStatus.State.Waiting.Reason
-				if reason == "ImagePullBackOff" || reason == "ErrImagePull" {
-					logger.Info("Detected image pull failure", "pod", pod.Name, "container", containerStatus.Name, "reason", reason)
-					return true, nil
-				}
-			}
+		return nil, err
+	}
+	pods := podList.Items
+	sort.SliceStable(pods, func(i, j int) bool {
+		if !pods[i].CreationTimestamp.Equal(&pods[j].CreationTimestamp) {
+			return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// containerStatuses records the state and last termination of each container
+// of the pod, init and sidecar containers first. A pod killed as a whole, such
+// as on its active deadline, reports its own reason for containers that carry none.
+func containerStatuses(pod *corev1.Pod) []v1alpha1.EvaContainerStatus {
+	statuses := []v1alpha1.EvaContainerStatus{}
+	for _, containerStatus := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		status := v1alpha1.EvaContainerStatus{
+			Name:         containerStatus.Name,
+			Pod:          pod.Name,
+			Type:         containerType(pod, containerStatus.Name),
+			Ready:        containerStatus.Ready,
+			RestartCount: containerStatus.RestartCount,
+		}
+		switch {
+		case containerStatus.State.Running != nil:
+			status.State = "Running"
+		case containerStatus.State.Terminated != nil:
+			status.State = "Terminated"
+		case containerStatus.State.Waiting != nil:
+			status.State = "Waiting"
+		}
+		terminated := containerStatus.State.Terminated
+		if terminated == nil {
+			terminated = containerStatus.LastTerminationState.Terminated
+		}
+		if terminated != nil {
+			exitCode := terminated.ExitCode
+			status.ExitCode = &exitCode
+			status.Reason = terminated.Reason
+			status.Message = terminated.Message
+		}
+		if status.Reason == "" && pod.Status.Reason != "" {
+			status.Reason = pod.Status.Reason
+			status.Message = pod.Status.Message
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// jobCompletionTime returns when the Job succeeded, or when it was marked failed
+func jobCompletionTime(job *kbatch.Job) *metav1.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == kbatch.JobFailed && condition.Status == corev1.ConditionTrue {
+			return &condition.LastTransitionTime
+		}
+	}
+	return nil
+}
+
+// isJobFinished reports whether the Job has reached the Complete or Failed condition
+func isJobFinished(job *kbatch.Job) bool {
+	return isJobConditionTrue(job, kbatch.JobComplete) || isJobConditionTrue(job, kbatch.JobFailed)
+}
+
+// isJobConditionTrue reports whether the Job has the given condition set to True
+func isJobConditionTrue(job *kbatch.Job, conditionType kbatch.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// getDeploymentState observes the rollout progress of the Deployment owned by this Eva
+func (r *EvaReconciler) getDeploymentState(ctx context.Context, eva *v1alpha1.Eva) (deploymentState, error) {
+	deploymentState := deploymentState{}
+	deployment, err := GetOwnedDeployment(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return deploymentState, err
+	}
+	if deployment == nil {
+		return deploymentState, nil
+	}
+
+	deploymentState.Exists = true
+	deploymentState.Replicas = 1
+	if deployment.Spec.Replicas != nil {
+		deploymentState.Replicas = *deployment.Spec.Replicas
+	}
+	deploymentState.UpdatedReplicas = deployment.Status.UpdatedReplicas
+	deploymentState.ReadyReplicas = deployment.Status.ReadyReplicas
+	deploymentState.AvailableReplicas = deployment.Status.AvailableReplicas
+
+	// Mirrors the checks done by `kubectl rollout status`
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			deploymentState.DeadlineExceeded = true
+		}
+	}
+	deploymentState.RolledOut = deployment.Generation <= deployment.Status.ObservedGeneration &&
+		deployment.Status.UpdatedReplicas >= deploymentState.Replicas &&
+		deployment.Status.Replicas <= deployment.Status.UpdatedReplicas &&
+		deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas
+	// Pods only count as ready once their readiness probe passes
+	deploymentState.Ready = deploymentState.RolledOut && deploymentState.AvailableReplicas >= deploymentState.Replicas &&
+		deploymentState.ReadyReplicas >= deploymentState.Replicas
+
+	return deploymentState, nil
+}
+
+// getServiceState observes the Service owned by this Eva
+func (r *EvaReconciler) getServiceState(ctx context.Context, eva *v1alpha1.Eva) (serviceState, error) {
+	serviceState := serviceState{}
+	service, err := GetOwnedService(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return serviceState, err
+	}
+	serviceState.Exists = service != nil
+	return serviceState, nil
+}
+
+// getStorageState observes the PersistentVolumeClaim owned by this Eva
+func (r *EvaReconciler) getStorageState(ctx context.Context, eva *v1alpha1.Eva) (storageState, error) {
+	storageState := storageState{}
+	pvc, err := GetOwnedPVC(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return storageState, err
+	}
+	storageState.Exists = pvc != nil
+	return storageState, nil
+}
+
+// getCronJobState observes the CronJob owned by this Eva and the Jobs it still keeps
+func (r *EvaReconciler) getCronJobState(ctx context.Context, eva *v1alpha1.Eva) (cronJobState, error) {
+	cronJobState := cronJobState{}
+	cronJob, err := GetOwnedCronJob(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return cronJobState, err
+	}
+	if cronJob == nil {
+		return cronJobState, nil
+	}
+
+	cronJobState.Exists = true
+	cronJobState.Suspended = cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+	cronJobState.LastScheduleTime = cronJob.Status.LastScheduleTime
+	cronJobState.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime
+
+	jobs, err := r.listScheduledJobs(ctx, cronJob)
+	if err != nil {
+		return cronJobState, err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		run := v1alpha1.EvaRunSummary{
+			JobName:        job.Name,
+			Phase:          v1alpha1.EvaPhaseRunning,
+			StartTime:      job.Status.StartTime,
+			CompletionTime: jobCompletionTime(job),
 		}
-		// Also check init container statuses
-		for _, containerStatus := range pod.Status.InitContainerStatuses {
-			if containerStatus.State.Waiting != nil {
-				reason := containerStatus.State.Waiting.Reason
-				if reason == "ImagePullBackOff" || reason == "ErrImagePull" {
-					logger.Info("Detected image pull failure in init container", "pod", pod.Name, "container", containerStatus.Name, "reason", reason)
-					return true, nil
-				}
+		switch {
+		case isJobConditionTrue(job, kbatch.JobComplete):
+			run.Phase = v1alpha1.EvaPhaseSucceeded
+		case isJobConditionTrue(job, kbatch.JobFailed):
+			run.Phase = v1alpha1.EvaPhaseFailed
+		default:
+			cronJobState.Active++
+		}
+		cronJobState.Runs = append(cronJobState.Runs, run)
+	}
+	if len(cronJobState.Runs) > maxRecentRuns {
+		cronJobState.Runs = cronJobState.Runs[len(cronJobState.Runs)-maxRecentRuns:]
+	}
+	return cronJobState, nil
+}
+
+// listScheduledJobs lists the Jobs started by the CronJob, oldest first
+func (r *EvaReconciler) listScheduledJobs(ctx context.Context, cronJob *kbatch.CronJob) ([]kbatch.Job, error) {
+	jobList := &kbatch.JobList{}
+	if err := r.List(ctx, jobList,
+		client.InNamespace(cronJob.Namespace),
+		client.MatchingFields{cronJobOwnerKey: string(cronJob.UID)}); err != nil {
+		return nil, err
+	}
+	jobs := jobList.Items
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if !jobs[i].CreationTimestamp.Equal(&jobs[j].CreationTimestamp) {
+			return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs, nil
+}
+
+// detectPodFailure returns the most severe failure among the pods of a Job,
+// ordered oldest first. Evictions and node loss only count for the newest
+// pod, since the Job replaces pods lost that way.
+func detectPodFailure(pods []corev1.Pod, logger logr.Logger) podFailure {
+	var worst podFailure
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		failure := classifyPod(pod, i == len(pods)-1)
+		if failure.Reason == "" {
+			continue
+		}
+		logger.Info("Detected pod failure", "pod", pod.Name, "reason", failure.Reason)
+		if pod.Status.StartTime != nil {
+			failure.Since = *pod.Status.StartTime
+		}
+		if worst.Reason == "" || podFailureSeverity[failure.Reason] > podFailureSeverity[worst.Reason] {
+			worst = failure
+		}
+	}
+	return worst
+}
+
+// podFailureSeverity ranks the pod failure reasons, highest first reported
+var podFailureSeverity = map[string]int{
+	podFailureInvalidImageName:           7,
+	podFailureImagePullBackOff:           6,
+	podFailureCreateContainerConfigError: 5,
+	podFailureCrashLoopBackOff:           4,
+	podFailureUnschedulable:              3,
+	podFailureNodeLost:                   2,
+	podFailureEvicted:                    1,
+}
+
+// classifyPod maps the state of a single pod to a pod failure
+func classifyPod(pod *corev1.Pod, newest bool) podFailure {
+	containerStatuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		waiting := containerStatus.State.Waiting
+		if waiting == nil {
+			continue
+		}
+		message := fmt.Sprintf("Pod %s container %s: %s", pod.Name, containerStatus.Name, waiting.Message)
+		switch waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull":
+			return podFailure{Reason: podFailureImagePullBackOff, Message: message, Fatal: true}
+		case "InvalidImageName":
+			return podFailure{Reason: podFailureInvalidImageName, Message: message, Fatal: true}
+		case "CreateContainerConfigError":
+			return podFailure{Reason: podFailureCreateContainerConfigError, Message: message}
+		case "CrashLoopBackOff":
+			return podFailure{Reason: podFailureCrashLoopBackOff, Message: message, Started: true}
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return podFailure{
+				Reason:  podFailureUnschedulable,
+				Message: fmt.Sprintf("Pod %s cannot be scheduled: %s", pod.Name, condition.Message),
 			}
 		}
 	}
 
-	return false, nil
+	if !newest {
+		return podFailure{}
+	}
+	switch {
+	case pod.Status.Reason == "NodeLost" || hasDisruption(pod, "DeletionByTaintManager", "DeletionByPodGC"):
+		return podFailure{
+			Reason:  podFailureNodeLost,
+			Message: fmt.Sprintf("Pod %s was lost with its node %s.", pod.Name, pod.Spec.NodeName),
+			Started: true,
+		}
+	case pod.Status.Reason == "Evicted" || hasDisruption(pod, "EvictionByEvictionAPI", "TerminationByKubelet", "PreemptionByScheduler"):
+		return podFailure{
+			Reason:  podFailureEvicted,
+			Message: fmt.Sprintf("Pod %s was evicted: %s", pod.Name, pod.Status.Message),
+			Started: true,
+		}
+	}
+	return podFailure{}
+}
+
+// hasDisruption reports whether the pod carries a DisruptionTarget condition with one of the reasons
+func hasDisruption(pod *corev1.Pod, reasons ...string) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue &&
+			slices.Contains(reasons, condition.Reason) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eva
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Pod failure detection", func() {
+	waitingPod := func(reason string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unit-01-job-abcde"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "unit-01-container",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+				}},
+			},
+		}
+	}
+	conditionPod := func(conditionType corev1.PodConditionType, status corev1.ConditionStatus, reason string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unit-01-job-abcde"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodPending,
+				Conditions: []corev1.PodCondition{{Type: conditionType, Status: status, Reason: reason}},
+			},
+		}
+	}
+
+	DescribeTable("classifying a single pod",
+		func(pod corev1.Pod, reason string, fatal, started bool) {
+			failure := detectPodFailure([]corev1.Pod{pod}, logf.Log)
+			Expect(failure.Reason).To(Equal(reason))
+			Expect(failure.Fatal).To(Equal(fatal))
+			Expect(failure.Started).To(Equal(started))
+		},
+		Entry("image pull back-off", waitingPod("ImagePullBackOff"), podFailureImagePullBackOff, true, false),
+		Entry("image pull error", waitingPod("ErrImagePull"), podFailureImagePullBackOff, true, false),
+		Entry("invalid image name", waitingPod("InvalidImageName"), podFailureInvalidImageName, true, false),
+		Entry("container config error", waitingPod("CreateContainerConfigError"), podFailureCreateContainerConfigError, false, false),
+		Entry("crash loop", waitingPod("CrashLoopBackOff"), podFailureCrashLoopBackOff, false, true),
+		Entry("still creating", waitingPod("ContainerCreating"), "", false, false),
+		Entry("unschedulable", conditionPod(corev1.PodScheduled, corev1.ConditionFalse, corev1.PodReasonUnschedulable), podFailureUnschedulable, false, false),
+		Entry("evicted", conditionPod(corev1.DisruptionTarget, corev1.ConditionTrue, "EvictionByEvictionAPI"), podFailureEvicted, false, true),
+		Entry("node lost", conditionPod(corev1.DisruptionTarget, corev1.ConditionTrue, "DeletionByTaintManager"), podFailureNodeLost, false, true),
+	)
+
+	It("should ignore evictions of pods the Job already replaced", func() {
+		evicted := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unit-01-job-old"},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+		}
+		replacement := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unit-01-job-new"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		Expect(detectPodFailure([]corev1.Pod{evicted, replacement}, logf.Log).Reason).To(BeEmpty())
+		Expect(detectPodFailure([]corev1.Pod{replacement, evicted}, logf.Log).Reason).To(Equal(podFailureEvicted))
+	})
+
+	It("should report the most severe failure across pods", func() {
+		pods := []corev1.Pod{
+			conditionPod(corev1.PodScheduled, corev1.ConditionFalse, corev1.PodReasonUnschedulable),
+			waitingPod("InvalidImageName"),
+		}
+		Expect(detectPodFailure(pods, logf.Log).Reason).To(Equal(podFailureInvalidImageName))
+	})
+})
@@ -0,0 +1,109 @@
+package eva
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// storageClaimName names the PersistentVolumeClaim provisioned for the Eva
+func storageClaimName(eva *v1alpha1.Eva) string {
+	return fmt.Sprintf("%s-storage", eva.Name)
+}
+
+// podVolumes returns the volumes and container mounts of the Eva's pods,
+// including the volume backed by the Eva's claim
+func podVolumes(eva *v1alpha1.Eva) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := slices.Clone(eva.Spec.Volumes)
+	mounts := slices.Clone(eva.Spec.VolumeMounts)
+	if eva.Spec.Storage != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: v1alpha1.EvaStorageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: storageClaimName(eva)},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      v1alpha1.EvaStorageVolumeName,
+			MountPath: eva.Spec.Storage.MountPath,
+		})
+	}
+	return volumes, mounts
+}
+
+func (r *EvaReconciler) desiredPVC(eva *v1alpha1.Eva) *corev1.PersistentVolumeClaim {
+	storage := eva.Spec.Storage
+	accessModes := storage.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return buildPVC(storageClaimName(eva), eva.Namespace,
+		WithPVCLabels(r.generateLabels(eva, nil)),
+		WithPVCSize(storage.Size),
+		WithPVCStorageClassName(storage.StorageClassName),
+		WithPVCAccessModes(accessModes))
+}
+
+// reconcileStorage creates the Eva's claim and expands it when the requested size grows
+func (r *EvaReconciler) reconcileStorage(ctx context.Context, eva *v1alpha1.Eva, storageState storageState, logger logr.Logger) error {
+	if eva.Spec.Storage == nil {
+		return nil
+	}
+	desired := r.desiredPVC(eva)
+	if !storageState.Exists {
+		if err := controllerutil.SetControllerReference(eva, desired, r.Scheme); err != nil {
+			logger.Error(err, "failed to set controller reference: ", "error", err)
+			return err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			logger.Error(err, "failed to create persistent volume claim: ", "error", err)
+			return err
+		}
+		logger.Info("Created PersistentVolumeClaim for Eva", "eva", eva.Name, "size", eva.Spec.Storage.Size.String())
+		return nil
+	}
+
+	existing, err := GetOwnedPVC(ctx, r.Client, eva, ownerKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	// Claims can only grow, and only the storage request may change
+	if eva.Spec.Storage.Size.Cmp(existing.Spec.Resources.Requests[corev1.ResourceStorage]) <= 0 {
+		return nil
+	}
+	patch := client.MergeFrom(existing.DeepCopy())
+	WithPVCSize(eva.Spec.Storage.Size)(existing)
+	logger.Info("Expanding PersistentVolumeClaim for Eva", "eva", eva.Name, "size", eva.Spec.Storage.Size.String())
+	return r.Patch(ctx, existing, patch)
+}
+
+// releaseStorage detaches the Eva's claim so it survives the Eva when the
+// storage is retained, or when the orphaned workload may still mount it
+func (r *EvaReconciler) releaseStorage(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	retain := eva.Spec.DeletionPolicy == v1alpha1.EvaDeletionPolicyOrphan ||
+		(eva.Spec.Storage != nil && eva.Spec.Storage.ReclaimPolicy == v1alpha1.EvaStorageReclaimPolicyRetain)
+	if !retain {
+		return nil
+	}
+	pvc, err := GetOwnedPVC(ctx, r.Client, eva, ownerKey)
+	if err != nil || pvc == nil {
+		return err
+	}
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if err := controllerutil.RemoveOwnerReference(eva, pvc, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Patch(ctx, pvc, patch); err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "failed to retain persistent volume claim: ", "error", err)
+		return err
+	}
+	logger.Info("Retained PersistentVolumeClaim of Eva", "eva", eva.Name, "pvc", pvc.Name)
+	return nil
+}
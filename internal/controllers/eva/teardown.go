@@ -0,0 +1,128 @@
+package eva
+
+import (
+	"context"
+	"time"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const defaultDeletionTimeout = 5 * time.Minute
+const terminationPollInterval = 2 * time.Second
+
+// stopWorkload suspends the Job or CronJob, or scales the Deployment to zero, and reports
+// how many of the Eva's pods are still running
+func (r *EvaReconciler) stopWorkload(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) (int, error) {
+	job, err := GetOwnedJob(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return 0, err
+	}
+	if job != nil && !isJobFinished(job) && (job.Spec.Suspend == nil || !*job.Spec.Suspend) {
+		if err := r.setJobSuspended(ctx, eva, true, logger); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := r.suspendScheduledJobs(ctx, eva, logger); err != nil {
+		return 0, err
+	}
+
+	deployment, err := GetOwnedDeployment(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return 0, err
+	}
+	if deployment != nil && (deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0) {
+		patch := client.MergeFrom(deployment.DeepCopy())
+		WithDeploymentReplicas(0)(deployment)
+		if err := r.Patch(ctx, deployment, patch); err != nil {
+			logger.Error(err, "failed to scale down deployment: ", "error", err)
+			return 0, err
+		}
+		logger.Info("Scaled Deployment to zero for teardown", "eva", eva.Name, "deployment", deployment.Name)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(eva.Namespace), client.MatchingLabels(r.generateLabels(eva, nil))); err != nil {
+		return 0, err
+	}
+	running := 0
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			running++
+		}
+	}
+	return running, nil
+}
+
+// orphanWorkload removes the Eva's owner reference from its children so they
+// survive garbage collection once the Eva is gone
+func (r *EvaReconciler) orphanWorkload(ctx context.Context, eva *v1alpha1.Eva, logger logr.Logger) error {
+	var children []client.Object
+	job, err := GetOwnedJob(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		children = append(children, job)
+	}
+	deployment, err := GetOwnedDeployment(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return err
+	}
+	if deployment != nil {
+		children = append(children, deployment)
+	}
+	cronJob, err := GetOwnedCronJob(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return err
+	}
+	if cronJob != nil {
+		children = append(children, cronJob)
+	}
+	service, err := GetOwnedService(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return err
+	}
+	if service != nil {
+		children = append(children, service)
+	}
+	permissions, err := r.ownedPermissions(ctx, eva, true)
+	if err != nil {
+		return err
+	}
+	children = append(children, permissions...)
+	secrets, err := GetOwnedSecrets(ctx, r.Client, eva, ownerKey)
+	if err != nil {
+		return err
+	}
+	for i := range secrets {
+		children = append(children, &secrets[i])
+	}
+
+	for _, child := range children {
+		patch := client.MergeFrom(child.DeepCopyObject().(client.Object))
+		if err := controllerutil.RemoveOwnerReference(eva, child, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Patch(ctx, child, patch); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to orphan child: ", "error", err, "child", child.GetName())
+			return err
+		}
+		logger.Info("Orphaned child of Eva", "eva", eva.Name, "child", child.GetName())
+	}
+	return nil
+}
+
+// deletionTimedOut reports whether teardown has waited longer than the Eva's deletion timeout
+func deletionTimedOut(eva *v1alpha1.Eva) bool {
+	timeout := defaultDeletionTimeout
+	if eva.Spec.DeletionTimeout != nil {
+		timeout = eva.Spec.DeletionTimeout.Duration
+	}
+	return time.Since(eva.DeletionTimestamp.Time) > timeout
+}
@@ -1,6 +1,11 @@
 package eva
 
-import ()
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+)
 
 type jobState struct {
 	Exists          bool
@@ -9,19 +14,109 @@ type jobState struct {
 	Succeeded       int32
 	FailedPods      int32
 	ImagePullFailed bool
+	Finished        bool
+	Failed          bool
+	Terminating     bool
+	Suspended       bool
+	SpecHash        string
+	Generation      int64
+	Ref             corev1.ObjectReference
+	StartTime       *metav1.Time
+	CompletionTime  *metav1.Time
+	PodRefs         []corev1.ObjectReference
+	Attempts        int32
+	Containers      []v1alpha1.EvaContainerStatus
+	PodFailure      podFailure
+	Attempt         int32
+	FailedReason    string
+	FailedMessage   string
+	Complete        bool
+	Parallelism     int32
+	// Completions is the number of pods that must succeed, zero for a work queue
+	Completions      int32
+	CompletedIndexes string
+	FailedIndexes    string
+	ResolvedImage    string
+}
+
+// podFailure describes the most severe problem found on the pods of a Job
+type podFailure struct {
+	// Reason is one of the podFailure* reasons, empty when the pods are healthy
+	Reason  string
+	Message string
+	// Fatal is set when the pods cannot recover without a spec change
+	Fatal bool
+	// Started is set when the pod got past scheduling and container creation
+	Started bool
+	// Since is when the failing pod was started by its node
+	Since metav1.Time
 }
 
+// Reasons reported on the Eva conditions for pod failures
+const (
+	podFailureImagePullBackOff           = "ImagePullBackOff"
+	podFailureInvalidImageName           = "InvalidImageName"
+	podFailureCreateContainerConfigError = "CreateContainerConfigError"
+	podFailureCrashLoopBackOff           = "CrashLoopBackOff"
+	podFailureUnschedulable              = "Unschedulable"
+	podFailureEvicted                    = "PodEvicted"
+	podFailureNodeLost                   = "NodeLost"
+)
+
 type deploymentState struct {
+	Exists            bool
+	Ready             bool
+	RolledOut         bool
+	DeadlineExceeded  bool
+	Replicas          int32
+	UpdatedReplicas   int32
+	ReadyReplicas     int32
+	AvailableReplicas int32
+}
+
+type cronJobState struct {
+	Exists             bool
+	Suspended          bool
+	Active             int32
+	LastScheduleTime   *metav1.Time
+	LastSuccessfulTime *metav1.Time
+	// Runs summarises the Jobs the CronJob still keeps, oldest first
+	Runs []v1alpha1.EvaRunSummary
+}
+
+type storageState struct {
 	Exists bool
-	Ready  bool
+}
+
+type namespaceState struct {
+	PodSecurityLevel string
 }
 
 type serviceState struct {
 	Exists bool
 }
 
+type pullSecretState struct {
+	// Missing lists the referenced pull secrets that do not exist
+	Missing []string
+	// Refused lists the shared pull secrets that may not be copied
+	Refused []string
+}
+
+type serviceAccountState struct {
+	Exists            bool
+	RoleExists        bool
+	RoleBindingExists bool
+}
+
 type evaCurrentState struct {
 	Job        jobState
 	Service    serviceState
 	Deployment deploymentState
+	CronJob    cronJobState
+	Storage    storageState
+	Namespace  namespaceState
+	// ServiceAccount observes the ServiceAccount, Role and RoleBinding the controller created
+	ServiceAccount serviceAccountState
+	PullSecrets    pullSecretState
 }
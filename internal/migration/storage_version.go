@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration rewrites stored Evas in the current storage version.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	geofrontv1beta1 "github.com/dayaliuzzo/Smooth-Operator/api/v1beta1"
+)
+
+// evaCRDName is the name of the CustomResourceDefinition serving Evas
+const evaCRDName = "evas.geofront.nerv.com"
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get,resourceNames=evas.geofront.nerv.com
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update;patch,resourceNames=evas.geofront.nerv.com
+
+// StorageVersionMigrator rewrites every Eva so the API server persists it in
+// the storage version, then drops the older versions from the CRD's
+// status.storedVersions so they can later be removed from the CRD.
+type StorageVersionMigrator struct {
+	// Client writes the Evas and the CRD status.
+	Client client.Client
+	// Reader lists Evas straight from the API server instead of the cache.
+	Reader client.Reader
+}
+
+// NeedLeaderElection makes only the leader migrate.
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the migration once when the manager starts.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	return m.Migrate(ctx)
+}
+
+// Migrate rewrites all Evas, then records the storage version as the only stored version.
+func (m *StorageVersionMigrator) Migrate(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("storage-migration")
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.Reader.Get(ctx, client.ObjectKey{Name: evaCRDName}, crd); err != nil {
+		return fmt.Errorf("failed to get CRD %s: %w", evaCRDName, err)
+	}
+	storageVersion := geofrontv1beta1.GroupVersion.Version
+	if slices.Equal(crd.Status.StoredVersions, []string{storageVersion}) {
+		log.Info("Stored Evas are already in the storage version", "version", storageVersion)
+		return nil
+	}
+
+	evas := &geofrontv1beta1.EvaList{}
+	if err := m.Reader.List(ctx, evas); err != nil {
+		return fmt.Errorf("failed to list Evas: %w", err)
+	}
+	for i := range evas.Items {
+		key := client.ObjectKeyFromObject(&evas.Items[i])
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			eva := &geofrontv1beta1.Eva{}
+			if err := m.Reader.Get(ctx, key, eva); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			// An unchanged update still makes the API server re-encode the
+			// object in the storage version.
+			return client.IgnoreNotFound(m.Client.Update(ctx, eva))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate Eva %s: %w", key, err)
+		}
+	}
+	log.Info("Rewrote stored Evas", "count", len(evas.Items), "version", storageVersion)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.Reader.Get(ctx, client.ObjectKey{Name: evaCRDName}, crd); err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = []string{storageVersion}
+		return m.Client.Status().Update(ctx, crd)
+	})
+}
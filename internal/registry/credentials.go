@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Auth is a username and password for a registry
+type Auth struct {
+	Username string
+	Password string
+}
+
+// Credentials holds the registry credentials of image pull secrets by
+// registry host
+type Credentials map[string]Auth
+
+// dockerConfigEntry is an entry of a .dockerconfigjson or .dockercfg file
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// CredentialsFromSecrets reads the registry credentials of image pull secrets.
+// Like the kubelet, the first secret holding credentials for a registry wins.
+func CredentialsFromSecrets(secrets []corev1.Secret) (Credentials, error) {
+	credentials := Credentials{}
+	for _, secret := range secrets {
+		var entries map[string]dockerConfigEntry
+		switch {
+		case len(secret.Data[corev1.DockerConfigJsonKey]) > 0:
+			var config struct {
+				Auths map[string]dockerConfigEntry `json:"auths"`
+			}
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+				return nil, fmt.Errorf("reading %s of secret %s: %w", corev1.DockerConfigJsonKey, secret.Name, err)
+			}
+			entries = config.Auths
+		case len(secret.Data[corev1.DockerConfigKey]) > 0:
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &entries); err != nil {
+				return nil, fmt.Errorf("reading %s of secret %s: %w", corev1.DockerConfigKey, secret.Name, err)
+			}
+		}
+		for server, entry := range entries {
+			auth, err := entry.auth()
+			if err != nil {
+				return nil, fmt.Errorf("reading credentials for %s of secret %s: %w", server, secret.Name, err)
+			}
+			host := normalizeHost(server)
+			if _, ok := credentials[host]; !ok {
+				credentials[host] = auth
+			}
+		}
+	}
+	return credentials, nil
+}
+
+// auth decodes the auth field when the username and password are not set
+func (entry dockerConfigEntry) auth() (Auth, error) {
+	if entry.Username != "" || entry.Auth == "" {
+		return Auth{Username: entry.Username, Password: entry.Password}, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return Auth{}, err
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return Auth{}, fmt.Errorf("auth is not of the form username:password")
+	}
+	return Auth{Username: username, Password: password}, nil
+}
+
+// normalizeHost strips the scheme and path docker config keys may carry and
+// folds the Docker Hub aliases into docker.io
+func normalizeHost(server string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "index.docker.io", dockerHubAPIHost:
+		return dockerHub
+	}
+	return host
+}
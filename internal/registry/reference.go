@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHub        = "docker.io"
+	dockerHubAPIHost = "registry-1.docker.io"
+)
+
+// reference is an image reference split into the parts the registry API uses
+type reference struct {
+	// Registry is the host of the registry, docker.io for Docker Hub
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseReference splits an image reference the way the container runtime
+// reads it: the first path component names the registry when it looks like a
+// host, and a missing tag means latest
+func parseReference(image string) (reference, error) {
+	ref := reference{}
+	name, digest, found := strings.Cut(image, "@")
+	if found {
+		ref.Digest = digest
+	}
+	if slash, colon := strings.LastIndex(name, "/"), strings.LastIndex(name, ":"); colon > slash {
+		name, ref.Tag = name[:colon], name[colon+1:]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	host, repository, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, ref.Repository = host, repository
+	} else {
+		ref.Registry, ref.Repository = dockerHub, name
+	}
+	if ref.Registry == dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" || strings.HasPrefix(ref.Repository, "/") || strings.HasSuffix(ref.Repository, "/") {
+		return ref, fmt.Errorf("invalid image reference %q", image)
+	}
+	return ref, nil
+}
+
+// apiHost is the host serving the registry API
+func (ref reference) apiHost() string {
+	if ref.Registry == dockerHub {
+		return dockerHubAPIHost
+	}
+	return ref.Registry
+}
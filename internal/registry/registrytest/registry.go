@@ -0,0 +1,110 @@
+// Package registrytest provides an in-process registry serving image
+// manifests through the registry v2 API, for testing digest resolution.
+package registrytest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+// Registry is a TLS registry holding pushed manifests. Its Client trusts
+// the registry's certificate.
+type Registry struct {
+	*httptest.Server
+	// Username and Password, when set, are required to get a pull token
+	// through the Bearer token flow
+	Username string
+	Password string
+	// OmitDigest leaves the Docker-Content-Digest header out of responses
+	OmitDigest bool
+
+	mu        sync.Mutex
+	manifests map[string][]byte
+}
+
+// New starts a registry. Callers should Close it when done.
+func New() *Registry {
+	registry := &Registry{manifests: map[string][]byte{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", registry.serveManifest)
+	mux.HandleFunc("/token", registry.serveToken)
+	registry.Server = httptest.NewTLSServer(mux)
+	return registry
+}
+
+// Host is the registry host to use in image references
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.URL, "https://")
+}
+
+// Push stores a manifest under the tag of the repository and returns its digest
+func (r *Registry) Push(repository, tag string, manifest []byte) string {
+	sum := sha256.Sum256(manifest)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifests[repository+":"+tag] = manifest
+	r.manifests[repository+"@"+digest] = manifest
+	return digest
+}
+
+// serveManifest serves /v2/<repository>/manifests/<tag or digest>
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	repository, reference, found := strings.Cut(path, "/manifests/")
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+	if r.Username != "" && req.Header.Get("Authorization") != "Bearer "+pullToken(repository) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registrytest",scope="repository:%s:pull"`,
+			r.URL, repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	separator := ":"
+	if strings.HasPrefix(reference, "sha256:") {
+		separator = "@"
+	}
+	r.mu.Lock()
+	manifest, ok := r.manifests[repository+separator+reference]
+	r.mu.Unlock()
+	if !ok {
+		http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+		return
+	}
+	sum := sha256.Sum256(manifest)
+	w.Header().Set("Content-Type", manifestMediaType)
+	if !r.OmitDigest {
+		w.Header().Set("Docker-Content-Digest", "sha256:"+hex.EncodeToString(sum[:]))
+	}
+	if req.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(manifest)
+}
+
+// serveToken issues a pull token for the requested repository
+func (r *Registry) serveToken(w http.ResponseWriter, req *http.Request) {
+	username, password, _ := req.BasicAuth()
+	if username != r.Username || password != r.Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	scope := req.URL.Query().Get("scope")
+	repository := strings.TrimSuffix(strings.TrimPrefix(scope, "repository:"), ":pull")
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": pullToken(repository)})
+}
+
+func pullToken(repository string) string {
+	return "pull-" + strings.ReplaceAll(repository, "/", "-")
+}
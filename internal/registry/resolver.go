@@ -0,0 +1,205 @@
+// Package registry resolves image tags to digests through the registry v2 API.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// manifestMediaTypes are accepted for a tag, the indexes first so that the
+// digest of a multi-platform image is the one every node can pull
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// defaultTimeout bounds a resolution when the Resolver sets no Timeout
+const defaultTimeout = 30 * time.Second
+
+// Resolver turns image tags into digests
+type Resolver struct {
+	// Client sends the registry requests, http.DefaultClient when nil
+	Client *http.Client
+	// Timeout bounds each call to Resolve, including the token exchange,
+	// so that an unresponsive registry fails the call. 30s when zero.
+	Timeout time.Duration
+}
+
+// Resolve returns the image pinned to the digest its tag points to, as
+// <image>@<digest>. Images that already carry a digest are returned as is.
+func (r *Resolver) Resolve(ctx context.Context, image string, credentials Credentials) (string, error) {
+	ref, err := parseReference(image)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return image, nil
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.apiHost(), ref.Repository, ref.Tag)
+	resp, err := r.fetchManifest(ctx, http.MethodHead, manifestURL, ref, credentials[ref.Registry])
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	digest := resp.Header.Get("Docker-Content-Digest")
+	// Registries need not send the digest, in which case it is computed from the manifest
+	if digest == "" {
+		resp, err = r.fetchManifest(ctx, http.MethodGet, manifestURL, ref, credentials[ref.Registry])
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, resp.Body); err != nil {
+			return "", fmt.Errorf("reading the manifest of %s: %w", image, err)
+		}
+		digest = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	}
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("the registry returned an unsupported digest %q for %s", digest, image)
+	}
+	return image + "@" + digest, nil
+}
+
+// fetchManifest requests the manifest, authenticating as the registry asks
+func (r *Resolver) fetchManifest(ctx context.Context, method, manifestURL string, ref reference, auth Auth) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return r.client().Do(req)
+	}
+
+	resp, err := send("")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		_ = resp.Body.Close()
+		authorization, err := r.authorize(ctx, resp.Header.Get("WWW-Authenticate"), ref, auth)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = send(authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("fetching the manifest of %s/%s:%s: %s", ref.Registry, ref.Repository, ref.Tag, resp.Status)
+	}
+	return resp, nil
+}
+
+// authorize answers the registry's challenge with the Authorization header to
+// send, fetching a pull token for the repository from a Bearer realm
+func (r *Resolver) authorize(ctx context.Context, challenge string, ref reference, auth Auth) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if auth.Username == "" {
+			return "", fmt.Errorf("the registry %s requires credentials", ref.Registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("the registry %s asks for unsupported authentication %q", ref.Registry, challenge)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("the registry %s sent an invalid token realm %q", ref.Registry, params["realm"])
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+	tokenURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth.Username != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching a pull token for %s/%s: %s", ref.Registry, ref.Repository, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("reading the pull token for %s/%s: %w", ref.Registry, ref.Repository, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+func (r *Resolver) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge splits a WWW-Authenticate header into its lowercased scheme
+// and its parameters, which may be quoted
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		rest = strings.TrimSpace(rest)
+	}
+	return strings.ToLower(scheme), params
+}
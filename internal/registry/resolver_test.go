@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dayaliuzzo/Smooth-Operator/internal/registry/registrytest"
+)
+
+var _ = Describe("Resolver", func() {
+	DescribeTable("image references",
+		func(image string, expected reference) {
+			Expect(parseReference(image)).To(Equal(expected))
+		},
+		Entry("an official image", "nginx",
+			reference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}),
+		Entry("a Docker Hub image with a tag", "bitnami/nginx:1.27",
+			reference{Registry: "docker.io", Repository: "bitnami/nginx", Tag: "1.27"}),
+		Entry("a registry with a port", "registry.nerv.com:5000/geofront/eva:01",
+			reference{Registry: "registry.nerv.com:5000", Repository: "geofront/eva", Tag: "01"}),
+		Entry("localhost", "localhost/eva",
+			reference{Registry: "localhost", Repository: "eva", Tag: "latest"}),
+		Entry("a digest", "ghcr.io/nerv/eva@sha256:abc",
+			reference{Registry: "ghcr.io", Repository: "nerv/eva", Digest: "sha256:abc"}),
+	)
+
+	It("should read the credentials of docker config secrets", func() {
+		auth := base64.StdEncoding.EncodeToString([]byte("shinji:unit-01"))
+		credentials, err := CredentialsFromSecrets([]corev1.Secret{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "hub"},
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+					`{"auths":{"https://index.docker.io/v1/":{"auth":"` + auth + `"}}}`)},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+				Data: map[string][]byte{corev1.DockerConfigKey: []byte(
+					`{"registry.nerv.com":{"username":"rei","password":"unit-00"},"docker.io":{"username":"asuka"}}`)},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(Equal(Credentials{
+			"docker.io":         {Username: "shinji", Password: "unit-01"},
+			"registry.nerv.com": {Username: "rei", Password: "unit-00"},
+		}))
+	})
+
+	Context("against a registry", func() {
+		var (
+			ctx      context.Context
+			server   *registrytest.Registry
+			resolver *Resolver
+			digest   string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			server = registrytest.New()
+			DeferCleanup(server.Close)
+			resolver = &Resolver{Client: server.Client()}
+			digest = server.Push("geofront/eva", "01", []byte(`{"schemaVersion":2}`))
+		})
+
+		It("should pin a tag to its digest", func() {
+			image := server.Host() + "/geofront/eva:01"
+			Expect(resolver.Resolve(ctx, image, nil)).To(Equal(image + "@" + digest))
+		})
+
+		It("should compute the digest when the registry does not send it", func() {
+			server.OmitDigest = true
+			image := server.Host() + "/geofront/eva:01"
+			Expect(resolver.Resolve(ctx, image, nil)).To(Equal(image + "@" + digest))
+		})
+
+		It("should keep an image that already has a digest", func() {
+			image := server.Host() + "/geofront/eva@" + digest
+			Expect(resolver.Resolve(ctx, image, nil)).To(Equal(image))
+		})
+
+		It("should fail for an unknown tag", func() {
+			_, err := resolver.Resolve(ctx, server.Host()+"/geofront/eva:02", nil)
+			Expect(err).To(MatchError(ContainSubstring("404")))
+		})
+
+		It("should get a pull token with the registry credentials", func() {
+			server.Username, server.Password = "shinji", "unit-01"
+			image := server.Host() + "/geofront/eva:01"
+			credentials := Credentials{server.Host(): {Username: "shinji", Password: "unit-01"}}
+			Expect(resolver.Resolve(ctx, image, credentials)).To(Equal(image + "@" + digest))
+
+			_, err := resolver.Resolve(ctx, image, Credentials{server.Host(): {Username: "shinji", Password: "wrong"}})
+			Expect(err).To(MatchError(ContainSubstring("pull token")))
+		})
+	})
+
+	It("should give up on a registry that does not answer", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		}))
+		DeferCleanup(server.Close)
+		resolver := &Resolver{Client: server.Client(), Timeout: 100 * time.Millisecond}
+		image := server.Listener.Addr().String() + "/geofront/eva:01"
+		_, err := resolver.Resolve(context.Background(), image, nil)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
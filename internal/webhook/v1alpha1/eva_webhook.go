@@ -0,0 +1,1237 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var evalog = logf.Log.WithName("eva-resource")
+
+// allowedColors are the paint schemes an Eva unit can be given
+var allowedColors = []string{"purple", "red", "blue", "orange", "yellow", "white", "black", "green", "pink"}
+
+// imageReferenceRegexp follows the reference grammar of the distribution
+// project: [domain[:port]/]path[/path...][:tag][@digest]
+var imageReferenceRegexp = func() *regexp.Regexp {
+	alphanumeric := `[a-z0-9]+`
+	separator := `(?:[._]|__|[-]+)`
+	pathComponent := alphanumeric + `(?:` + separator + alphanumeric + `)*`
+	domainComponent := `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	host := `(?:` + domainComponent + `(?:\.` + domainComponent + `)*|\[[a-fA-F0-9:]+\])`
+	domain := host + `(?::[0-9]+)?`
+	name := `(?:` + domain + `/)?` + pathComponent + `(?:/` + pathComponent + `)*`
+	tag := `[\w][\w.-]{0,127}`
+	digest := `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+	return regexp.MustCompile(`^` + name + `(?::` + tag + `)?(?:@` + digest + `)?$`)
+}()
+
+// imageTagOrDigestRegexp matches the optional tag and digest suffix of an image reference
+var imageTagOrDigestRegexp = regexp.MustCompile(`[:@][^/]*$`)
+
+// maxImageNameLength is the longest repository name a registry accepts
+const maxImageNameLength = 255
+
+// cronDescriptors are the predefined schedules accepted in place of the five cron fields
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// cronFieldRegexp matches one field of a cron schedule, such as */5, 1-5 or MON,WED
+var cronFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*?/,-]+$`)
+
+// maxCronJobNameLength is the longest CronJob name, leaving room for the
+// suffix of the Jobs it starts
+const maxCronJobNameLength = 52
+
+// EvaDefaults holds the operator-wide defaults applied to new Evas at admission.
+type EvaDefaults struct {
+	// ImagePullSecret is set on Evas that reference none, provided a Secret
+	// with that name exists in the Eva's namespace.
+	ImagePullSecret string
+	// Resources fills in the container requests and limits an Eva leaves unset.
+	Resources corev1.ResourceRequirements
+	// BackoffLimit is the number of pod retries for Evas that do not set one.
+	BackoffLimit int32
+}
+
+// SetupEvaWebhookWithManager registers the webhook for Eva in the manager.
+func SetupEvaWebhookWithManager(mgr ctrl.Manager, defaults EvaDefaults) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&geofrontv1alpha1.Eva{}).
+		WithValidator(&EvaCustomValidator{Reader: mgr.GetAPIReader(), Reviewer: mgr.GetClient()}).
+		WithDefaulter(&EvaCustomDefaulter{Reader: mgr.GetAPIReader(), Defaults: defaults}).
+		Complete()
+}
+
+// Defaults are only applied on creation: the controller updates Evas to manage
+// its finalizer, and defaulting those updates would silently change the spec
+// of existing units and rerun their Jobs.
+// +kubebuilder:webhook:path=/mutate-geofront-nerv-com-v1alpha1-eva,mutating=true,failurePolicy=fail,sideEffects=None,groups=geofront.nerv.com,resources=evas,verbs=create,versions=v1alpha1,name=meva-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// EvaCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind Eva when those are created.
+type EvaCustomDefaulter struct {
+	// Reader looks up the default image pull secret in the Eva's namespace.
+	Reader   client.Reader
+	Defaults EvaDefaults
+}
+
+var _ webhook.CustomDefaulter = &EvaCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Eva.
+func (d *EvaCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	eva, ok := obj.(*geofrontv1alpha1.Eva)
+	if !ok {
+		return fmt.Errorf("expected an Eva object but got %T", obj)
+	}
+	evalog.Info("Defaulting for Eva", "name", eva.GetName())
+
+	d.defaultLabels(eva)
+	if err := d.defaultImagePullSecret(ctx, eva); err != nil {
+		return err
+	}
+	if eva.Spec.Resources.Requests == nil && len(d.Defaults.Resources.Requests) > 0 {
+		eva.Spec.Resources.Requests = d.Defaults.Resources.Requests.DeepCopy()
+	}
+	if eva.Spec.Resources.Limits == nil && len(d.Defaults.Resources.Limits) > 0 {
+		eva.Spec.Resources.Limits = d.Defaults.Resources.Limits.DeepCopy()
+	}
+	// Indexed Jobs retrying per index are only bounded by backoffLimit when it is set explicitly
+	if eva.Spec.BackoffLimit == nil && eva.Spec.BackoffLimitPerIndex == nil {
+		backoffLimit := d.Defaults.BackoffLimit
+		eva.Spec.BackoffLimit = &backoffLimit
+	}
+	return nil
+}
+
+// defaultLabels adds the recommended app.kubernetes.io labels without overriding any set by the user
+func (d *EvaCustomDefaulter) defaultLabels(eva *geofrontv1alpha1.Eva) {
+	component := "job"
+	switch eva.Spec.Mode {
+	case geofrontv1alpha1.EvaModeDeployment:
+		component = "deployment"
+	case geofrontv1alpha1.EvaModeCronJob:
+		component = "cronjob"
+	}
+	if eva.Labels == nil {
+		eva.Labels = map[string]string{}
+	}
+	for k, v := range map[string]string{
+		"app.kubernetes.io/name":       "eva",
+		"app.kubernetes.io/instance":   eva.Name,
+		"app.kubernetes.io/component":  component,
+		"app.kubernetes.io/part-of":    "smooth-operator",
+		"app.kubernetes.io/managed-by": "smooth-operator",
+	} {
+		if _, exists := eva.Labels[k]; !exists {
+			eva.Labels[k] = v
+		}
+	}
+}
+
+// defaultImagePullSecret sets the operator's default pull secret when the Eva
+// has none and the Secret is present in the Eva's namespace
+func (d *EvaCustomDefaulter) defaultImagePullSecret(ctx context.Context, eva *geofrontv1alpha1.Eva) error {
+	if eva.Spec.ImagePullSecret != "" || len(eva.Spec.ImagePullSecrets) > 0 || d.Defaults.ImagePullSecret == "" || d.Reader == nil {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	err := d.Reader.Get(ctx, types.NamespacedName{Namespace: eva.Namespace, Name: d.Defaults.ImagePullSecret}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	eva.Spec.ImagePullSecret = d.Defaults.ImagePullSecret
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-geofront-nerv-com-v1alpha1-eva,mutating=false,failurePolicy=fail,sideEffects=None,groups=geofront.nerv.com,resources=evas,verbs=create;update,versions=v1alpha1,name=veva-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// EvaCustomValidator struct is responsible for validating the Eva resource
+// when it is created, updated, or deleted.
+type EvaCustomValidator struct {
+	// Reader looks up referenced objects such as image pull secrets. It should
+	// read from the API server directly so that Secrets are not cached.
+	Reader client.Reader
+	// Reviewer asks the API server whether the requesting user holds the
+	// permissions an Eva grants its ServiceAccount. Nil skips the check.
+	Reviewer client.Client
+}
+
+var _ webhook.CustomValidator = &EvaCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Eva.
+func (v *EvaCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	eva, ok := obj.(*geofrontv1alpha1.Eva)
+	if !ok {
+		return nil, fmt.Errorf("expected a Eva object but got %T", obj)
+	}
+	evalog.Info("Validation for Eva upon creation", "name", eva.GetName())
+
+	return nil, v.validateEva(ctx, nil, eva)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Eva.
+func (v *EvaCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	eva, ok := newObj.(*geofrontv1alpha1.Eva)
+	if !ok {
+		return nil, fmt.Errorf("expected a Eva object for the newObj but got %T", newObj)
+	}
+	oldEva, ok := oldObj.(*geofrontv1alpha1.Eva)
+	if !ok {
+		return nil, fmt.Errorf("expected a Eva object for the oldObj but got %T", oldObj)
+	}
+	evalog.Info("Validation for Eva upon update", "name", eva.GetName())
+
+	// Never block the controller from removing its finalizer
+	if !eva.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, v.validateEva(ctx, oldEva, eva)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Eva.
+func (v *EvaCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateEva checks the spec of eva. On update, oldEva is set and only the
+// fields that changed are validated, so objects admitted before a rule
+// existed can still be updated.
+func (v *EvaCustomValidator) validateEva(ctx context.Context, oldEva, eva *geofrontv1alpha1.Eva) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	var oldSpec *geofrontv1alpha1.EvaSpec
+	if oldEva != nil {
+		oldSpec = &oldEva.Spec
+	}
+	changed := func(get func(spec *geofrontv1alpha1.EvaSpec) any) bool {
+		return oldSpec == nil || !equality.Semantic.DeepEqual(get(oldSpec), get(&eva.Spec))
+	}
+
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Image }) {
+		allErrs = append(allErrs, validateImage(specPath.Child("image"), eva.Spec.Image)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Command }) {
+		for i, entry := range eva.Spec.Command {
+			if entry == "" {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("command").Index(i), entry, "command entries must not be empty"))
+			}
+		}
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Env }) {
+		allErrs = append(allErrs, validateEnv(specPath.Child("env"), eva.Spec.Env)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Ports }) {
+		allErrs = append(allErrs, validatePorts(specPath.Child("ports"), eva.Spec.Ports)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any {
+		return []any{s.InitContainers, s.Sidecars, s.Volumes, s.Storage}
+	}) {
+		allErrs = append(allErrs, validateExtraContainers(specPath, eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any {
+		return []any{s.Volumes, s.VolumeMounts, s.Storage}
+	}) {
+		allErrs = append(allErrs, validateVolumes(specPath, eva)...)
+	}
+	if oldSpec != nil && oldSpec.Storage != nil {
+		allErrs = append(allErrs, validateStorageUpdate(specPath.Child("storage"), oldSpec.Storage, eva.Spec.Storage)...)
+	}
+	if eva.Spec.Probes != nil && changed(func(s *geofrontv1alpha1.EvaSpec) any { return []any{s.Probes, s.Ports, s.Mode} }) {
+		allErrs = append(allErrs, validateProbes(specPath.Child("probes"), eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Scheduling }) {
+		allErrs = append(allErrs, validateScheduling(specPath.Child("scheduling"), eva.Spec.Scheduling)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Color }) && eva.Spec.Color != "" &&
+		!slices.Contains(allowedColors, eva.Spec.Color) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("color"), eva.Spec.Color, allowedColors))
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.ImagePullSecret }) && eva.Spec.ImagePullSecret != "" {
+		if err := v.validateSecretExists(ctx, specPath.Child("imagePullSecret"), eva.Namespace, eva.Spec.ImagePullSecret); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.ImagePullSecrets }) {
+		allErrs = append(allErrs, v.validateImagePullSecrets(ctx, specPath.Child("imagePullSecrets"), eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return []any{s.ServiceAccount, s.Permissions} }) {
+		allErrs = append(allErrs, validatePermissions(specPath, eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Permissions }) && len(allErrs) == 0 {
+		allErrs = append(allErrs, v.validatePermissionsHeld(ctx, specPath.Child("permissions"), eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.PodFailurePolicy }) && eva.Spec.PodFailurePolicy != nil {
+		allErrs = append(allErrs, validatePodFailurePolicy(specPath.Child("podFailurePolicy"), eva)...)
+	}
+	if eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment {
+		if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.ActiveDeadlineSeconds }) && eva.Spec.ActiveDeadlineSeconds != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("activeDeadlineSeconds"), "only applies to Job mode"))
+		}
+		if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.TTLSecondsAfterFinished }) && eva.Spec.TTLSecondsAfterFinished != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("ttlSecondsAfterFinished"), "only applies to Job mode"))
+		}
+		if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Parallelism }) && eva.Spec.Parallelism != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("parallelism"), "only applies to Job mode; use replicas instead"))
+		}
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return []any{s.ResolveImageDigest, s.Mode} }) && eva.Spec.ResolveImageDigest &&
+		(eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment || eva.Spec.Mode == geofrontv1alpha1.EvaModeCronJob) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("resolveImageDigest"), "only applies to Job mode"))
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any {
+		return []any{s.Completions, s.CompletionMode, s.SuccessPolicy, s.BackoffLimitPerIndex, s.Mode}
+	}) {
+		allErrs = append(allErrs, validateCompletions(specPath, eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.Schedule }) {
+		allErrs = append(allErrs, validateSchedule(specPath.Child("schedule"), eva)...)
+	}
+	if changed(func(s *geofrontv1alpha1.EvaSpec) any { return s.RetryPolicy }) && eva.Spec.RetryPolicy != nil {
+		allErrs = append(allErrs, validateRetryPolicy(specPath.Child("retryPolicy"), eva)...)
+	}
+	if oldEva != nil {
+		allErrs = append(allErrs, validateImmutableWhileActive(specPath, oldEva, eva)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(geofrontv1alpha1.GroupVersion.WithKind("Eva").GroupKind(), eva.Name, allErrs)
+}
+
+// validatePodFailurePolicy checks that the rules match the Eva's container and apply to Job mode
+func validatePodFailurePolicy(path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	if eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment {
+		allErrs = append(allErrs, field.Forbidden(path, "only applies to Job mode"))
+	}
+	containerName := fmt.Sprintf("%s-container", eva.Name)
+	for i, rule := range eva.Spec.PodFailurePolicy.Rules {
+		if rule.OnExitCodes != nil && rule.OnExitCodes.ContainerName != nil && *rule.OnExitCodes.ContainerName != containerName {
+			allErrs = append(allErrs, field.Invalid(path.Child("rules").Index(i).Child("onExitCodes", "containerName"),
+				*rule.OnExitCodes.ContainerName, fmt.Sprintf("must be omitted or name the Eva's container %q", containerName)))
+		}
+	}
+	return allErrs
+}
+
+// validateCompletions checks that completion settings apply to Job mode and
+// that the indexed ones are only set on an Indexed Job
+func validateCompletions(specPath *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	if eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment {
+		for _, setting := range []struct {
+			name string
+			set  bool
+		}{
+			{"completions", eva.Spec.Completions != nil},
+			{"completionMode", eva.Spec.CompletionMode != ""},
+			{"successPolicy", eva.Spec.SuccessPolicy != nil},
+			{"backoffLimitPerIndex", eva.Spec.BackoffLimitPerIndex != nil},
+		} {
+			if setting.set {
+				allErrs = append(allErrs, field.Forbidden(specPath.Child(setting.name), "only applies to Job mode"))
+			}
+		}
+		return allErrs
+	}
+	if eva.Spec.CompletionMode != batchv1.IndexedCompletion {
+		if eva.Spec.SuccessPolicy != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("successPolicy"), "only applies to the Indexed completion mode"))
+		}
+		if eva.Spec.BackoffLimitPerIndex != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("backoffLimitPerIndex"), "only applies to the Indexed completion mode"))
+		}
+		return allErrs
+	}
+	if eva.Spec.Completions == nil {
+		return append(allErrs, field.Required(specPath.Child("completions"), "must be set in the Indexed completion mode"))
+	}
+	if eva.Spec.SuccessPolicy == nil {
+		return allErrs
+	}
+
+	completions := *eva.Spec.Completions
+	rulesPath := specPath.Child("successPolicy", "rules")
+	for i, rule := range eva.Spec.SuccessPolicy.Rules {
+		path := rulesPath.Index(i)
+		if rule.SucceededIndexes == nil && rule.SucceededCount == nil {
+			allErrs = append(allErrs, field.Required(path, "must set succeededIndexes, succeededCount or both"))
+			continue
+		}
+		indexes := completions
+		if rule.SucceededIndexes != nil {
+			count, err := countIndexes(*rule.SucceededIndexes, completions)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("succeededIndexes"), *rule.SucceededIndexes, err.Error()))
+				continue
+			}
+			indexes = count
+		}
+		if rule.SucceededCount != nil && (*rule.SucceededCount < 1 || *rule.SucceededCount > indexes) {
+			allErrs = append(allErrs, field.Invalid(path.Child("succeededCount"), *rule.SucceededCount,
+				fmt.Sprintf("must be between 1 and the %d indexes the rule covers", indexes)))
+		}
+	}
+	return allErrs
+}
+
+// countIndexes parses an ordered list of indexes and ranges, such as
+// "0,2-4", and returns how many indexes it holds
+func countIndexes(indexes string, completions int32) (int32, error) {
+	var count int32
+	last := int64(-1)
+	for _, interval := range strings.Split(indexes, ",") {
+		first, end, isRange := strings.Cut(interval, "-")
+		low, err := strconv.ParseInt(first, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an index", first)
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.ParseInt(end, 10, 32); err != nil {
+				return 0, fmt.Errorf("%q is not an index", end)
+			}
+		}
+		switch {
+		case low <= last || high < low:
+			return 0, errors.New("indexes must be listed in increasing order without overlap")
+		case high >= int64(completions):
+			return 0, fmt.Errorf("index %d is not below completions (%d)", high, completions)
+		}
+		count += int32(high - low + 1)
+		last = high
+	}
+	return count, nil
+}
+
+// validateRetryPolicy checks that retries apply to a Job-mode Eva and that the backoff bounds are ordered
+func validateRetryPolicy(path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	policy := eva.Spec.RetryPolicy
+	if eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment || eva.Spec.Mode == geofrontv1alpha1.EvaModeCronJob {
+		allErrs = append(allErrs, field.Forbidden(path, "retries only apply to Job mode"))
+	}
+	if policy.Backoff != nil && policy.MaxBackoff != nil && policy.MaxBackoff.Duration < policy.Backoff.Duration {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxBackoff"), policy.MaxBackoff.Duration.String(), "must not be shorter than backoff"))
+	}
+	if policy.Backoff != nil && policy.Backoff.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("backoff"), policy.Backoff.Duration.String(), "must not be negative"))
+	}
+	return allErrs
+}
+
+// validateEnv checks that each variable takes its value from exactly one place
+func validateEnv(path *field.Path, env []corev1.EnvVar) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, envVar := range env {
+		if envVar.ValueFrom == nil {
+			continue
+		}
+		if envVar.Value != "" {
+			allErrs = append(allErrs, field.Invalid(path.Index(i).Child("valueFrom"), "",
+				"may not be specified when value is not empty"))
+			continue
+		}
+		sources := 0
+		for _, set := range []bool{envVar.ValueFrom.SecretKeyRef != nil, envVar.ValueFrom.ConfigMapKeyRef != nil,
+			envVar.ValueFrom.FieldRef != nil, envVar.ValueFrom.ResourceFieldRef != nil, envVar.ValueFrom.FileKeyRef != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			allErrs = append(allErrs, field.Invalid(path.Index(i).Child("valueFrom"), "",
+				"must specify exactly one of secretKeyRef, configMapKeyRef, fieldRef, resourceFieldRef or fileKeyRef"))
+		}
+	}
+	return allErrs
+}
+
+// validatePorts checks that no container port is declared twice for the same protocol
+func validatePorts(path *field.Path, ports []corev1.ContainerPort) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[corev1.ContainerPort]bool{}
+	for i, port := range ports {
+		key := corev1.ContainerPort{ContainerPort: port.ContainerPort, Protocol: port.Protocol}
+		if key.Protocol == "" {
+			key.Protocol = corev1.ProtocolTCP
+		}
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), fmt.Sprintf("%d/%s", key.ContainerPort, key.Protocol)))
+		}
+		seen[key] = true
+	}
+	return allErrs
+}
+
+// validateVolumes checks that volumes use the supported sources and that
+// every mount refers to one of them without clashing with the Eva's storage
+func validateVolumes(specPath *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+	for i, volume := range eva.Spec.Volumes {
+		path := specPath.Child("volumes").Index(i)
+		if volume.Name == geofrontv1alpha1.EvaStorageVolumeName || volume.Name == geofrontv1alpha1.EvaTmpVolumeName {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), volume.Name, "is reserved for the volumes the controller adds"))
+		}
+		names[volume.Name] = true
+		source := volume.VolumeSource
+		supported := 0
+		for _, set := range []bool{source.ConfigMap != nil, source.Secret != nil, source.EmptyDir != nil, source.Projected != nil} {
+			if set {
+				supported++
+			}
+		}
+		if supported != 1 || !equality.Semantic.DeepEqual(source, volumeSourceOf(source)) {
+			allErrs = append(allErrs, field.Invalid(path, volume.Name, "must set exactly one of configMap, secret, emptyDir or projected"))
+		}
+	}
+	for i, mount := range eva.Spec.VolumeMounts {
+		path := specPath.Child("volumeMounts").Index(i)
+		if !names[mount.Name] {
+			allErrs = append(allErrs, field.NotFound(path.Child("name"), mount.Name))
+		}
+		if eva.Spec.Storage != nil && mount.MountPath == eva.Spec.Storage.MountPath {
+			allErrs = append(allErrs, field.Duplicate(path.Child("mountPath"), mount.MountPath))
+		}
+	}
+	return allErrs
+}
+
+// validateExtraContainers checks the init and sidecar containers: their names
+// must be unique across the pod, and their volume mounts must refer to the
+// Eva's volumes or storage
+func validateExtraContainers(specPath *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{fmt.Sprintf("%s-container", eva.Name): true}
+	volumes := map[string]bool{}
+	for _, volume := range eva.Spec.Volumes {
+		volumes[volume.Name] = true
+	}
+	if eva.Spec.Storage != nil {
+		volumes[geofrontv1alpha1.EvaStorageVolumeName] = true
+	}
+	validate := func(path *field.Path, container corev1.Container) {
+		if names[container.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), container.Name))
+		}
+		names[container.Name] = true
+		allErrs = append(allErrs, validateImage(path.Child("image"), container.Image)...)
+		allErrs = append(allErrs, validateEnv(path.Child("env"), container.Env)...)
+		allErrs = append(allErrs, validatePorts(path.Child("ports"), container.Ports)...)
+		for i, mount := range container.VolumeMounts {
+			if !volumes[mount.Name] {
+				allErrs = append(allErrs, field.NotFound(path.Child("volumeMounts").Index(i).Child("name"), mount.Name))
+			}
+		}
+	}
+	for i, sidecar := range eva.Spec.Sidecars {
+		path := specPath.Child("sidecars").Index(i)
+		validate(path, sidecar)
+		if sidecar.RestartPolicy != nil && *sidecar.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+			allErrs = append(allErrs, field.NotSupported(path.Child("restartPolicy"), *sidecar.RestartPolicy,
+				[]corev1.ContainerRestartPolicy{corev1.ContainerRestartPolicyAlways}))
+		}
+	}
+	for i, container := range eva.Spec.InitContainers {
+		path := specPath.Child("initContainers").Index(i)
+		validate(path, container)
+		if container.RestartPolicy != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("restartPolicy"), "is only set on sidecars; use spec.sidecars instead"))
+		}
+		if container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil {
+			allErrs = append(allErrs, field.Forbidden(path, "init containers run to completion and cannot be probed"))
+		}
+		if container.Lifecycle != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("lifecycle"), "init containers cannot have lifecycle hooks"))
+		}
+	}
+	return allErrs
+}
+
+// volumeSourceOf keeps only the supported sources of a volume
+func volumeSourceOf(source corev1.VolumeSource) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		ConfigMap: source.ConfigMap,
+		Secret:    source.Secret,
+		EmptyDir:  source.EmptyDir,
+		Projected: source.Projected,
+	}
+}
+
+// validateStorageUpdate rejects changes a bound claim cannot follow
+func validateStorageUpdate(path *field.Path, oldStorage, storage *geofrontv1alpha1.EvaStorage) field.ErrorList {
+	if storage == nil {
+		return field.ErrorList{field.Forbidden(path, "cannot be removed once set")}
+	}
+	var allErrs field.ErrorList
+	if storage.Size.Cmp(oldStorage.Size) < 0 {
+		allErrs = append(allErrs, field.Forbidden(path.Child("size"), "can be increased but not decreased"))
+	}
+	if !equality.Semantic.DeepEqual(oldStorage.StorageClassName, storage.StorageClassName) {
+		allErrs = append(allErrs, field.Forbidden(path.Child("storageClassName"), "is immutable"))
+	}
+	if !equality.Semantic.DeepEqual(oldStorage.AccessModes, storage.AccessModes) {
+		allErrs = append(allErrs, field.Forbidden(path.Child("accessModes"), "is immutable"))
+	}
+	return allErrs
+}
+
+// validateProbes checks that probes apply to Deployment mode and that each one is well formed
+func validateProbes(path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	if eva.Spec.Mode != geofrontv1alpha1.EvaModeDeployment {
+		allErrs = append(allErrs, field.Forbidden(path, "only applies to Deployment mode"))
+	}
+	probes := eva.Spec.Probes
+	allErrs = append(allErrs, validateProbe(path.Child("liveness"), probes.Liveness, eva.Spec.Ports, true)...)
+	allErrs = append(allErrs, validateProbe(path.Child("readiness"), probes.Readiness, eva.Spec.Ports, false)...)
+	allErrs = append(allErrs, validateProbe(path.Child("startup"), probes.Startup, eva.Spec.Ports, true)...)
+	if probes.Readiness != nil && probes.Readiness.TerminationGracePeriodSeconds != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("readiness", "terminationGracePeriodSeconds"), "only applies to liveness and startup probes"))
+	}
+	return allErrs
+}
+
+// validateProbe checks that a probe has exactly one handler, targets a valid
+// port and has sensible thresholds. Liveness and startup probes must succeed once.
+func validateProbe(path *field.Path, probe *corev1.Probe, ports []corev1.ContainerPort, singleSuccess bool) field.ErrorList {
+	if probe == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	handler := probe.ProbeHandler
+	handlers := 0
+	for _, set := range []bool{handler.Exec != nil, handler.HTTPGet != nil, handler.TCPSocket != nil, handler.GRPC != nil} {
+		if set {
+			handlers++
+		}
+	}
+	if handlers != 1 {
+		allErrs = append(allErrs, field.Invalid(path, handlers, "must set exactly one of exec, httpGet, tcpSocket or grpc"))
+	}
+	if handler.Exec != nil && len(handler.Exec.Command) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("exec", "command"), "must not be empty"))
+	}
+	if handler.HTTPGet != nil {
+		allErrs = append(allErrs, validateProbePort(path.Child("httpGet", "port"), handler.HTTPGet.Port, ports)...)
+	}
+	if handler.TCPSocket != nil {
+		allErrs = append(allErrs, validateProbePort(path.Child("tcpSocket", "port"), handler.TCPSocket.Port, ports)...)
+	}
+	if handler.GRPC != nil && (handler.GRPC.Port < 1 || handler.GRPC.Port > 65535) {
+		allErrs = append(allErrs, field.Invalid(path.Child("grpc", "port"), handler.GRPC.Port, "must be between 1 and 65535"))
+	}
+	for _, setting := range []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", probe.InitialDelaySeconds},
+		{"timeoutSeconds", probe.TimeoutSeconds},
+		{"periodSeconds", probe.PeriodSeconds},
+		{"successThreshold", probe.SuccessThreshold},
+		{"failureThreshold", probe.FailureThreshold},
+	} {
+		if setting.value < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child(setting.name), setting.value, "must not be negative"))
+		}
+	}
+	if singleSuccess && probe.SuccessThreshold > 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("successThreshold"), probe.SuccessThreshold, "must be 1"))
+	}
+	return allErrs
+}
+
+// validateProbePort checks that a probe port is in range or names a declared container port
+func validateProbePort(path *field.Path, port intstr.IntOrString, ports []corev1.ContainerPort) field.ErrorList {
+	if port.Type == intstr.Int {
+		if port.IntVal < 1 || port.IntVal > 65535 {
+			return field.ErrorList{field.Invalid(path, port.IntVal, "must be between 1 and 65535")}
+		}
+		return nil
+	}
+	if !slices.ContainsFunc(ports, func(p corev1.ContainerPort) bool { return p.Name == port.StrVal }) {
+		return field.ErrorList{field.NotFound(path, port.StrVal)}
+	}
+	return nil
+}
+
+// validateScheduling checks the placement of the pods for settings the
+// scheduler would reject or silently ignore
+func validateScheduling(path *field.Path, scheduling geofrontv1alpha1.EvaSchedulingSpec) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(scheduling.NodeSelector, path.Child("nodeSelector"))
+	for i, toleration := range scheduling.Tolerations {
+		allErrs = append(allErrs, validateToleration(path.Child("tolerations").Index(i), toleration)...)
+	}
+	if scheduling.Affinity != nil {
+		allErrs = append(allErrs, validateAffinity(path.Child("affinity"), scheduling.Affinity)...)
+	}
+	allErrs = append(allErrs, validateTopologySpread(path.Child("topologySpreadConstraints"), scheduling.TopologySpreadConstraints)...)
+	if scheduling.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(scheduling.PriorityClassName) {
+			allErrs = append(allErrs, field.Invalid(path.Child("priorityClassName"), scheduling.PriorityClassName, msg))
+		}
+	}
+	if scheduling.RuntimeClassName != nil {
+		for _, msg := range validation.IsDNS1123Subdomain(*scheduling.RuntimeClassName) {
+			allErrs = append(allErrs, field.Invalid(path.Child("runtimeClassName"), *scheduling.RuntimeClassName, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateToleration checks the operator, key, value and effect of a toleration agree
+func validateToleration(path *field.Path, toleration corev1.Toleration) field.ErrorList {
+	var allErrs field.ErrorList
+	if toleration.Key != "" {
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(toleration.Key, path.Child("key"))...)
+	}
+	switch toleration.Operator {
+	case corev1.TolerationOpEqual, "":
+		if toleration.Key == "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("operator"), toleration.Operator, "must be Exists when key is empty"))
+		}
+	case corev1.TolerationOpExists:
+		if toleration.Value != "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("value"), toleration.Value, "must be empty when operator is Exists"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("operator"), toleration.Operator,
+			[]corev1.TolerationOperator{corev1.TolerationOpEqual, corev1.TolerationOpExists}))
+	}
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("effect"), toleration.Effect,
+			[]corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute}))
+	}
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		allErrs = append(allErrs, field.Invalid(path.Child("effect"), toleration.Effect, "must be NoExecute when tolerationSeconds is set"))
+	}
+	return allErrs
+}
+
+// validateAffinity checks node selector terms and pod affinity terms
+func validateAffinity(path *field.Path, affinity *corev1.Affinity) field.ErrorList {
+	var allErrs field.ErrorList
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		nodePath := path.Child("nodeAffinity")
+		if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			termsPath := nodePath.Child("requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+			if len(required.NodeSelectorTerms) == 0 {
+				allErrs = append(allErrs, field.Required(termsPath, "must have at least one node selector term"))
+			}
+			for i, term := range required.NodeSelectorTerms {
+				allErrs = append(allErrs, validateNodeSelectorTerm(termsPath.Index(i), term)...)
+			}
+		}
+		for i, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			preferredPath := nodePath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+			if preferred.Weight < 1 || preferred.Weight > 100 {
+				allErrs = append(allErrs, field.Invalid(preferredPath.Child("weight"), preferred.Weight, "must be between 1 and 100"))
+			}
+			allErrs = append(allErrs, validateNodeSelectorTerm(preferredPath.Child("preference"), preferred.Preference)...)
+		}
+	}
+	if affinity.PodAffinity != nil {
+		allErrs = append(allErrs, validatePodAffinityTerms(path.Child("podAffinity"),
+			affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution)...)
+	}
+	if affinity.PodAntiAffinity != nil {
+		allErrs = append(allErrs, validatePodAffinityTerms(path.Child("podAntiAffinity"),
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)...)
+	}
+	return allErrs
+}
+
+// validateNodeSelectorTerm checks that a term selects something and that its requirements are valid
+func validateNodeSelectorTerm(path *field.Path, term corev1.NodeSelectorTerm) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		allErrs = append(allErrs, field.Required(path, "must set matchExpressions or matchFields"))
+	}
+	allErrs = append(allErrs, validateNodeSelectorRequirements(path.Child("matchExpressions"), term.MatchExpressions)...)
+	allErrs = append(allErrs, validateNodeSelectorRequirements(path.Child("matchFields"), term.MatchFields)...)
+	return allErrs
+}
+
+// validateNodeSelectorRequirements checks that each requirement carries the values its operator needs
+func validateNodeSelectorRequirements(path *field.Path, requirements []corev1.NodeSelectorRequirement) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, requirement := range requirements {
+		valuesPath := path.Index(i).Child("values")
+		switch requirement.Operator {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(requirement.Values) == 0 {
+				allErrs = append(allErrs, field.Required(valuesPath, "must be set for In and NotIn"))
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(requirement.Values) > 0 {
+				allErrs = append(allErrs, field.Forbidden(valuesPath, "must be empty for Exists and DoesNotExist"))
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(requirement.Values) != 1 {
+				allErrs = append(allErrs, field.Invalid(valuesPath, requirement.Values, "must hold a single integer for Gt and Lt"))
+			} else if _, err := strconv.ParseInt(requirement.Values[0], 10, 64); err != nil {
+				allErrs = append(allErrs, field.Invalid(valuesPath.Index(0), requirement.Values[0], "must be an integer for Gt and Lt"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(path.Index(i).Child("operator"), requirement.Operator,
+				[]corev1.NodeSelectorOperator{corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn, corev1.NodeSelectorOpExists,
+					corev1.NodeSelectorOpDoesNotExist, corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt}))
+		}
+	}
+	return allErrs
+}
+
+// validatePodAffinityTerms checks the required and preferred terms of a pod (anti-)affinity
+func validatePodAffinityTerms(path *field.Path, required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm) field.ErrorList {
+	var allErrs field.ErrorList
+	validateTerm := func(termPath *field.Path, term corev1.PodAffinityTerm) {
+		if term.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(termPath.Child("topologyKey"), "must name a node label"))
+		} else {
+			allErrs = append(allErrs, metav1validation.ValidateLabelName(term.TopologyKey, termPath.Child("topologyKey"))...)
+		}
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(term.LabelSelector,
+			metav1validation.LabelSelectorValidationOptions{}, termPath.Child("labelSelector"))...)
+	}
+	for i, term := range required {
+		validateTerm(path.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i), term)
+	}
+	for i, weighted := range preferred {
+		weightedPath := path.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if weighted.Weight < 1 || weighted.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(weightedPath.Child("weight"), weighted.Weight, "must be between 1 and 100"))
+		}
+		validateTerm(weightedPath.Child("podAffinityTerm"), weighted.PodAffinityTerm)
+	}
+	return allErrs
+}
+
+// validateTopologySpread checks each constraint and that no two constraints
+// share a topology key and unsatisfiable action
+func validateTopologySpread(path *field.Path, constraints []corev1.TopologySpreadConstraint) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	for i, constraint := range constraints {
+		constraintPath := path.Index(i)
+		if constraint.MaxSkew < 1 {
+			allErrs = append(allErrs, field.Invalid(constraintPath.Child("maxSkew"), constraint.MaxSkew, "must be at least 1"))
+		}
+		if constraint.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(constraintPath.Child("topologyKey"), "must name a node label"))
+		}
+		switch constraint.WhenUnsatisfiable {
+		case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+		default:
+			allErrs = append(allErrs, field.NotSupported(constraintPath.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable,
+				[]corev1.UnsatisfiableConstraintAction{corev1.DoNotSchedule, corev1.ScheduleAnyway}))
+		}
+		if constraint.MinDomains != nil {
+			if *constraint.MinDomains < 1 {
+				allErrs = append(allErrs, field.Invalid(constraintPath.Child("minDomains"), *constraint.MinDomains, "must be at least 1"))
+			} else if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+				allErrs = append(allErrs, field.Forbidden(constraintPath.Child("minDomains"), "only applies with whenUnsatisfiable DoNotSchedule"))
+			}
+		}
+		key := fmt.Sprintf("%s/%s", constraint.TopologyKey, constraint.WhenUnsatisfiable)
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(constraintPath, key))
+		}
+		seen[key] = true
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(constraint.LabelSelector,
+			metav1validation.LabelSelectorValidationOptions{}, constraintPath.Child("labelSelector"))...)
+		for j, labelKey := range constraint.MatchLabelKeys {
+			if constraint.LabelSelector != nil && hasSelectorKey(constraint.LabelSelector, labelKey) {
+				allErrs = append(allErrs, field.Invalid(constraintPath.Child("matchLabelKeys").Index(j), labelKey,
+					"must not also appear in labelSelector"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// hasSelectorKey reports whether a label selector constrains the given key
+func hasSelectorKey(selector *metav1.LabelSelector, key string) bool {
+	if _, ok := selector.MatchLabels[key]; ok {
+		return true
+	}
+	return slices.ContainsFunc(selector.MatchExpressions, func(requirement metav1.LabelSelectorRequirement) bool {
+		return requirement.Key == key
+	})
+}
+
+// validateSchedule checks that a schedule is set exactly in CronJob mode, that
+// its cron expression and time zone parse, and that the CronJob name fits
+func validateSchedule(path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	schedule := eva.Spec.Schedule
+	if eva.Spec.Mode != geofrontv1alpha1.EvaModeCronJob {
+		if schedule != nil {
+			return field.ErrorList{field.Forbidden(path, "only applies to CronJob mode")}
+		}
+		return nil
+	}
+	if schedule == nil {
+		return field.ErrorList{field.Required(path, "a schedule is required in CronJob mode")}
+	}
+
+	var allErrs field.ErrorList
+	if err := validateCron(schedule.Cron); err != "" {
+		allErrs = append(allErrs, field.Invalid(path.Child("cron"), schedule.Cron, err))
+	}
+	if schedule.TimeZone != nil {
+		if _, err := time.LoadLocation(*schedule.TimeZone); err != nil || *schedule.TimeZone == "" || *schedule.TimeZone == "Local" {
+			allErrs = append(allErrs, field.Invalid(path.Child("timeZone"), *schedule.TimeZone, "must be an IANA time zone name, e.g. Asia/Tokyo"))
+		}
+	}
+	if name := eva.Name + "-cronjob"; len(name) > maxCronJobNameLength {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), eva.Name,
+			fmt.Sprintf("must be no more than %d characters in CronJob mode", maxCronJobNameLength-len("-cronjob"))))
+	}
+	return allErrs
+}
+
+// validateCron returns why a cron expression is invalid, or an empty string.
+// The CronJob controller parses the expression in full; this catches the
+// mistakes that would otherwise only surface on the CronJob.
+func validateCron(cron string) string {
+	if strings.Contains(cron, "TZ") {
+		return "must not set a time zone; use timeZone instead"
+	}
+	if strings.HasPrefix(cron, "@") {
+		if slices.Contains(cronDescriptors, cron) || strings.HasPrefix(cron, "@every ") {
+			return ""
+		}
+		return fmt.Sprintf("must be one of %s or five cron fields", strings.Join(cronDescriptors, ", "))
+	}
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return "must have five fields: minute, hour, day of month, month and day of week"
+	}
+	for _, f := range fields {
+		if !cronFieldRegexp.MatchString(f) {
+			return fmt.Sprintf("field %q is not a valid cron field", f)
+		}
+	}
+	return ""
+}
+
+// validateImage checks an image reference against the distribution reference grammar
+func validateImage(path *field.Path, image string) field.ErrorList {
+	if image == "" {
+		return field.ErrorList{field.Required(path, "an image reference is required")}
+	}
+	if !imageReferenceRegexp.MatchString(image) {
+		return field.ErrorList{field.Invalid(path, image, "must be a valid image reference, e.g. registry.example.com/team/image:tag")}
+	}
+	name := image
+	if i := imageTagOrDigestRegexp.FindStringIndex(image); i != nil {
+		name = image[:i[0]]
+	}
+	if len(name) > maxImageNameLength {
+		return field.ErrorList{field.TooLong(path, name, maxImageNameLength)}
+	}
+	return nil
+}
+
+// validateImmutableWhileActive rejects changes to the fields a Job is built
+// from while that Job is running, unless the Eva opted into replacing it
+func validateImmutableWhileActive(specPath *field.Path, oldEva, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	// Deployments roll and CronJobs pick up the change on their next run
+	if eva.Spec.Mode == geofrontv1alpha1.EvaModeDeployment || eva.Spec.Mode == geofrontv1alpha1.EvaModeCronJob ||
+		eva.Spec.UpdateStrategy == geofrontv1alpha1.EvaUpdateStrategyReplace {
+		return nil
+	}
+	switch oldEva.Status.Phase {
+	case geofrontv1alpha1.EvaPhasePending, geofrontv1alpha1.EvaPhaseRunning, geofrontv1alpha1.EvaPhasePaused:
+	default:
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	message := fmt.Sprintf("cannot be changed while the Eva is %s; wait for the run to finish or set updateStrategy to %s",
+		oldEva.Status.Phase, geofrontv1alpha1.EvaUpdateStrategyReplace)
+	if oldEva.Spec.Image != eva.Spec.Image {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("image"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Command, eva.Spec.Command) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("command"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Args, eva.Spec.Args) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("args"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Env, eva.Spec.Env) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("env"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.EnvFrom, eva.Spec.EnvFrom) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("envFrom"), message))
+	}
+	if oldEva.Spec.WorkingDir != eva.Spec.WorkingDir {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("workingDir"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Ports, eva.Spec.Ports) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("ports"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Volumes, eva.Spec.Volumes) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("volumes"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.VolumeMounts, eva.Spec.VolumeMounts) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("volumeMounts"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.InitContainers, eva.Spec.InitContainers) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("initContainers"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Sidecars, eva.Spec.Sidecars) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("sidecars"), message))
+	}
+	if oldEva.Spec.ImagePullSecret != eva.Spec.ImagePullSecret {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("imagePullSecret"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.ImagePullSecrets, eva.Spec.ImagePullSecrets) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("imagePullSecrets"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.ServiceAccount, eva.Spec.ServiceAccount) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("serviceAccount"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Resources, eva.Spec.Resources) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("resources"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.BackoffLimit, eva.Spec.BackoffLimit) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("backoffLimit"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.ActiveDeadlineSeconds, eva.Spec.ActiveDeadlineSeconds) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("activeDeadlineSeconds"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.PodFailurePolicy, eva.Spec.PodFailurePolicy) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("podFailurePolicy"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Completions, eva.Spec.Completions) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("completions"), message))
+	}
+	if oldEva.Spec.CompletionMode != eva.Spec.CompletionMode {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("completionMode"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.SuccessPolicy, eva.Spec.SuccessPolicy) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("successPolicy"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.BackoffLimitPerIndex, eva.Spec.BackoffLimitPerIndex) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("backoffLimitPerIndex"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.Scheduling, eva.Spec.Scheduling) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("scheduling"), message))
+	}
+	if !equality.Semantic.DeepEqual(oldEva.Spec.SecurityContext, eva.Spec.SecurityContext) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("securityContext"), message))
+	}
+	return allErrs
+}
+
+// validateImagePullSecrets checks the names of the pull secrets and of the
+// copies the controller makes of shared ones, and that the Secrets of the Eva's
+// namespace exist, as for spec.imagePullSecret. Shared sources are not looked
+// up here; the controller reports them on PullSecretMissing.
+func (v *EvaCustomValidator) validateImagePullSecrets(ctx context.Context, path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, secret := range eva.Spec.ImagePullSecrets {
+		name := secret.Name
+		if secret.Shared {
+			name = fmt.Sprintf("%s-%s", eva.Name, secret.Name)
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(path.Index(i).Child("name"), secret.Name, msg))
+		}
+		if secret.Shared {
+			continue
+		}
+		if err := v.validateSecretExists(ctx, path.Index(i).Child("name"), eva.Namespace, secret.Name); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return allErrs
+}
+
+// escalatingVerbs would let the ServiceAccount grant itself or others more
+// than the Eva's author holds
+var escalatingVerbs = []string{"escalate", "bind", "impersonate"}
+
+// validatePermissions checks the ServiceAccount an Eva runs as and the rules
+// granted to it. Wildcards are rejected so that the grant stays reviewable.
+func validatePermissions(specPath *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	var allErrs field.ErrorList
+	serviceAccount := eva.Spec.ServiceAccount
+	if serviceAccount != nil && serviceAccount.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(serviceAccount.Name) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("serviceAccount", "name"), serviceAccount.Name, msg))
+		}
+	}
+	path := specPath.Child("permissions")
+	if len(eva.Spec.Permissions) > 0 && (serviceAccount == nil || !serviceAccount.Create) {
+		allErrs = append(allErrs, field.Forbidden(path, "requires serviceAccount.create"))
+	}
+	for i, rule := range eva.Spec.Permissions {
+		rulePath := path.Index(i)
+		if len(rule.NonResourceURLs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(rulePath.Child("nonResourceURLs"), "a namespaced Role cannot grant non-resource URLs"))
+		}
+		if len(rule.Verbs) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath.Child("verbs"), ""))
+		}
+		if len(rule.Resources) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath.Child("resources"), ""))
+		}
+		for j, verb := range rule.Verbs {
+			if verb == rbacv1.VerbAll || slices.Contains(escalatingVerbs, verb) {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("verbs").Index(j), fmt.Sprintf("the %q verb may not be granted", verb)))
+			}
+		}
+		for j, group := range rule.APIGroups {
+			if group == rbacv1.APIGroupAll {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("apiGroups").Index(j), "wildcards may not be granted"))
+			}
+		}
+		for j, resource := range rule.Resources {
+			if resource == rbacv1.ResourceAll || strings.HasPrefix(resource, "*/") {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("resources").Index(j), "wildcards may not be granted"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validatePermissionsHeld checks through SubjectAccessReviews that the user
+// creating or updating the Eva holds every permission it grants in the Eva's
+// namespace, so an Eva cannot be used to escalate privileges
+func (v *EvaCustomValidator) validatePermissionsHeld(ctx context.Context, path *field.Path, eva *geofrontv1alpha1.Eva) field.ErrorList {
+	if v.Reviewer == nil || len(eva.Spec.Permissions) == 0 {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for key, value := range req.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	var allErrs field.ErrorList
+	for i, rule := range eva.Spec.Permissions {
+		names := rule.ResourceNames
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				resource, subresource, _ := strings.Cut(resource, "/")
+				for _, verb := range rule.Verbs {
+					for _, name := range names {
+						review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+							User:   req.UserInfo.Username,
+							UID:    req.UserInfo.UID,
+							Groups: req.UserInfo.Groups,
+							Extra:  extra,
+							ResourceAttributes: &authorizationv1.ResourceAttributes{
+								Namespace:   eva.Namespace,
+								Verb:        verb,
+								Group:       group,
+								Resource:    resource,
+								Subresource: subresource,
+								Name:        name,
+							},
+						}}
+						if err := v.Reviewer.Create(ctx, review); err != nil {
+							return append(allErrs, field.InternalError(path.Index(i), err))
+						}
+						if !review.Status.Allowed {
+							allErrs = append(allErrs, field.Forbidden(path.Index(i),
+								fmt.Sprintf("%s may not %s %s", req.UserInfo.Username, verb, describeResource(group, resource, subresource, name))))
+						}
+					}
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+// describeResource formats a resource the way kubectl auth can-i takes it
+func describeResource(group, resource, subresource, name string) string {
+	if group != "" {
+		resource += "." + group
+	}
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	if name != "" {
+		resource += " " + name
+	}
+	return resource
+}
+
+// validateSecretExists checks that the named Secret exists in the namespace
+func (v *EvaCustomValidator) validateSecretExists(ctx context.Context, path *field.Path, namespace, name string) *field.Error {
+	if v.Reader == nil {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	err := v.Reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret)
+	if apierrors.IsNotFound(err) {
+		return field.NotFound(path, name)
+	}
+	if err != nil {
+		return field.InternalError(path, err)
+	}
+	return nil
+}
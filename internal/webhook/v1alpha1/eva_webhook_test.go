@@ -0,0 +1,499 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	// TODO (user): Add any additional imports if needed
+)
+
+var _ = Describe("Eva Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *geofrontv1alpha1.Eva
+		oldObj    *geofrontv1alpha1.Eva
+		validator EvaCustomValidator
+		defaulter EvaCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &geofrontv1alpha1.Eva{
+			ObjectMeta: metav1.ObjectMeta{Name: "eva-01", Namespace: "default"},
+			Spec: geofrontv1alpha1.EvaSpec{
+				Image:   "registry.nerv.com:5000/geofront/eva:01",
+				Color:   "purple",
+				Command: []string{"/bin/sh", "-c", "echo sortie"},
+			},
+		}
+		oldObj = obj.DeepCopy()
+		reader := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nerv-registry", Namespace: "default"},
+		}).Build()
+		validator = EvaCustomValidator{Reader: reader}
+		defaulter = EvaCustomDefaulter{
+			Reader: reader,
+			Defaults: EvaDefaults{
+				ImagePullSecret: "nerv-registry",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				},
+				BackoffLimit: 2,
+			},
+		}
+	})
+
+	Context("When creating Eva under Defaulting Webhook", func() {
+		It("Should apply the operator defaults", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Labels).To(HaveKeyWithValue("app.kubernetes.io/instance", "eva-01"))
+			Expect(obj.Labels).To(HaveKeyWithValue("app.kubernetes.io/component", "job"))
+			Expect(obj.Spec.ImagePullSecret).To(Equal("nerv-registry"))
+			Expect(obj.Spec.Resources.Requests).To(HaveKey(corev1.ResourceCPU))
+			Expect(obj.Spec.Resources.Limits).To(BeNil())
+			Expect(obj.Spec.BackoffLimit).To(HaveValue(Equal(int32(2))))
+		})
+
+		It("Should not override values set by the user", func() {
+			backoffLimit := int32(5)
+			obj.Labels = map[string]string{"app.kubernetes.io/name": "unit-01"}
+			obj.Spec.ImagePullSecret = "pilot-registry"
+			obj.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}
+			obj.Spec.BackoffLimit = &backoffLimit
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "unit-01"))
+			Expect(obj.Spec.ImagePullSecret).To(Equal("pilot-registry"))
+			Expect(obj.Spec.Resources.Requests).NotTo(HaveKey(corev1.ResourceCPU))
+			Expect(obj.Spec.BackoffLimit).To(HaveValue(Equal(int32(5))))
+		})
+
+		It("Should not default the image pull secret of an Eva listing its own", func() {
+			obj.Spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{{Name: "mirror-registry"}}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ImagePullSecret).To(BeEmpty())
+		})
+
+		It("Should skip a default image pull secret missing from the namespace", func() {
+			obj.Namespace = "tokyo-3"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ImagePullSecret).To(BeEmpty())
+		})
+	})
+
+	Context("When creating or updating Eva under Validating Webhook", func() {
+		It("Should admit a valid Eva", func() {
+			obj.Spec.ImagePullSecret = "nerv-registry"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		DescribeTable("image references",
+			func(image string, valid bool) {
+				obj.Spec.Image = image
+				_, err := validator.ValidateCreate(ctx, obj)
+				if valid {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(HaveOccurred())
+				}
+			},
+			Entry("short name", "nginx", true),
+			Entry("tag", "nginx:latest", true),
+			Entry("digest", "nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true),
+			Entry("registry with port", "localhost:5000/team/app:v1.2.3", true),
+			Entry("empty", "", false),
+			Entry("uppercase repository", "Nginx:latest", false),
+			Entry("trailing colon", "nginx:", false),
+			Entry("whitespace", "nginx latest", false),
+		)
+
+		It("Should deny an empty command entry", func() {
+			obj.Spec.Command = []string{"/bin/sh", ""}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.command[1]")))
+		})
+
+		It("Should deny an environment variable with both a value and a source", func() {
+			obj.Spec.Env = []corev1.EnvVar{{
+				Name:  "TOKEN",
+				Value: "plain",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "nerv-token"},
+					Key:                  "token",
+				}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.env[0].valueFrom")))
+		})
+
+		It("Should deny a container port declared twice", func() {
+			obj.Spec.Ports = []corev1.ContainerPort{
+				{Name: "http", ContainerPort: 8080},
+				{Name: "alt", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.ports[1]")))
+		})
+
+		It("Should deny a volume source other than configMap, secret, emptyDir or projected", func() {
+			obj.Spec.Volumes = []corev1.Volume{{
+				Name:         "host",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.volumes[0]")))
+		})
+
+		It("Should deny a volume named like the writable /tmp volume", func() {
+			obj.Spec.Volumes = []corev1.Volume{{
+				Name:         geofrontv1alpha1.EvaTmpVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.volumes[0].name")))
+		})
+
+		It("Should deny a volume mount without a matching volume", func() {
+			obj.Spec.VolumeMounts = []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.volumeMounts[0].name")))
+		})
+
+		DescribeTable("scheduling constraints",
+			func(scheduling geofrontv1alpha1.EvaSchedulingSpec, invalidField string) {
+				obj.Spec.Scheduling = scheduling
+				_, err := validator.ValidateCreate(ctx, obj)
+				if invalidField == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(ContainSubstring(invalidField)))
+				}
+			},
+			Entry("a zone spread with a toleration", geofrontv1alpha1.EvaSchedulingSpec{
+				Tolerations: []corev1.Toleration{{Key: "nerv.com/eva", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+					MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule,
+				}},
+			}, ""),
+			Entry("a toleration matching any value with a value", geofrontv1alpha1.EvaSchedulingSpec{
+				Tolerations: []corev1.Toleration{{Key: "nerv.com/eva", Operator: corev1.TolerationOpExists, Value: "01"}},
+			}, "spec.scheduling.tolerations[0].value"),
+			Entry("a toleration period on a NoSchedule taint", geofrontv1alpha1.EvaSchedulingSpec{
+				Tolerations: []corev1.Toleration{{
+					Key: "nerv.com/eva", Value: "01", Effect: corev1.TaintEffectNoSchedule, TolerationSeconds: ptr.To(int64(30)),
+				}},
+			}, "spec.scheduling.tolerations[0].effect"),
+			Entry("a Gt requirement without an integer", geofrontv1alpha1.EvaSchedulingSpec{
+				Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "nerv.com/sync", Operator: corev1.NodeSelectorOpGt, Values: []string{"high"}}},
+					}}},
+				}},
+			}, "matchExpressions[0].values[0]"),
+			Entry("two spreads over the same topology", geofrontv1alpha1.EvaSchedulingSpec{
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule},
+					{MaxSkew: 2, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule},
+				},
+			}, "spec.scheduling.topologySpreadConstraints[1]"),
+			Entry("minimum domains on a best-effort spread", geofrontv1alpha1.EvaSchedulingSpec{
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+					MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.ScheduleAnyway, MinDomains: ptr.To(int32(3)),
+				}},
+			}, "spec.scheduling.topologySpreadConstraints[0].minDomains"),
+			Entry("an invalid priority class name", geofrontv1alpha1.EvaSchedulingSpec{PriorityClassName: "Eva_Critical"},
+				"spec.scheduling.priorityClassName"),
+		)
+
+		It("Should deny probes on a Job-mode Eva", func() {
+			obj.Spec.Probes = &geofrontv1alpha1.EvaProbes{Liveness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.probes: Forbidden")))
+		})
+
+		It("Should deny a probe on a port the container does not declare", func() {
+			obj.Spec.Mode = geofrontv1alpha1.EvaModeDeployment
+			obj.Spec.Probes = &geofrontv1alpha1.EvaProbes{Readiness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("http")}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.probes.readiness.httpGet.port")))
+		})
+
+		It("Should deny a liveness probe that must succeed more than once", func() {
+			obj.Spec.Mode = geofrontv1alpha1.EvaModeDeployment
+			obj.Spec.Probes = &geofrontv1alpha1.EvaProbes{Liveness: &corev1.Probe{
+				ProbeHandler:     corev1.ProbeHandler{GRPC: &corev1.GRPCAction{Port: 9090}},
+				SuccessThreshold: 3,
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.probes.liveness.successThreshold")))
+		})
+
+		It("Should deny a sidecar named like the Eva's container", func() {
+			obj.Spec.Sidecars = []corev1.Container{{Name: obj.Name + "-container", Image: "fluent/fluent-bit:3.0"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.sidecars[0].name: Duplicate value")))
+		})
+
+		It("Should deny a probe on an init container", func() {
+			obj.Spec.InitContainers = []corev1.Container{{
+				Name:  "migrate",
+				Image: "busybox:1.36",
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}},
+				},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.initContainers[0]: Forbidden")))
+		})
+
+		It("Should deny an unknown color", func() {
+			obj.Spec.Color = "beige"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.color")))
+		})
+
+		It("Should deny an image pull secret that does not exist", func() {
+			obj.Spec.ImagePullSecret = "missing"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.imagePullSecret")))
+		})
+
+		Context("When an Eva grants its ServiceAccount permissions", func() {
+			var reviewed []authorizationv1.ResourceAttributes
+
+			BeforeEach(func() {
+				reviewed = nil
+				obj.Spec.ServiceAccount = &geofrontv1alpha1.EvaServiceAccount{Create: true}
+				obj.Spec.Permissions = []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"configmaps", "pods/log"},
+					Verbs:     []string{"get"},
+				}}
+				validator.Reviewer = interceptor.NewClient(fake.NewClientBuilder().WithScheme(testScheme).Build(), interceptor.Funcs{
+					Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+						review := obj.(*authorizationv1.SubjectAccessReview)
+						Expect(review.Spec.User).To(Equal("shinji"))
+						reviewed = append(reviewed, *review.Spec.ResourceAttributes)
+						review.Status.Allowed = review.Spec.ResourceAttributes.Resource == "configmaps"
+						return nil
+					},
+				})
+				ctx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: "shinji"},
+				}})
+			})
+
+			It("Should deny permissions the requesting user does not hold", func() {
+				Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("shinji may not get pods/log")))
+				Expect(reviewed).To(ConsistOf(
+					authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Resource: "configmaps"},
+					authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Resource: "pods", Subresource: "log"},
+				))
+			})
+
+			It("Should admit permissions the requesting user holds", func() {
+				obj.Spec.Permissions[0].Resources = []string{"configmaps"}
+				Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			})
+
+			It("Should deny wildcards and escalating verbs", func() {
+				obj.Spec.Permissions[0].Verbs = []string{"*", "bind"}
+				obj.Spec.Permissions[0].Resources = []string{"*"}
+				_, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).To(MatchError(ContainSubstring("spec.permissions[0].verbs[0]")))
+				Expect(err).To(MatchError(ContainSubstring("spec.permissions[0].verbs[1]")))
+				Expect(err).To(MatchError(ContainSubstring("spec.permissions[0].resources[0]")))
+				Expect(reviewed).To(BeEmpty())
+			})
+
+			It("Should deny permissions for a ServiceAccount the controller does not create", func() {
+				obj.Spec.ServiceAccount = &geofrontv1alpha1.EvaServiceAccount{Name: "builder"}
+				Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.permissions: Forbidden")))
+			})
+
+			It("Should not review unchanged permissions on update", func() {
+				oldObj = obj.DeepCopy()
+				obj.Spec.Color = "red"
+				Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+				Expect(reviewed).To(BeEmpty())
+			})
+		})
+
+		It("Should deny image pull secrets that do not exist", func() {
+			obj.Spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{{Name: "nerv-registry"}, {Name: "missing"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.imagePullSecrets[1].name: Not found")))
+		})
+
+		It("Should admit shared pull secrets without looking them up", func() {
+			obj.Spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{{Name: "nerv-registry"}, {Name: "missing", Shared: true}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a shared pull secret whose copy would get an invalid name", func() {
+			obj.Spec.ImagePullSecrets = []geofrontv1alpha1.EvaImagePullSecret{{Name: strings.Repeat("a", 250), Shared: true}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.imagePullSecrets[0].name")))
+		})
+
+		It("Should deny digest resolution on a CronJob-mode Eva", func() {
+			obj.Spec.Mode = geofrontv1alpha1.EvaModeCronJob
+			obj.Spec.Schedule = &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}
+			obj.Spec.ResolveImageDigest = true
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.resolveImageDigest: Forbidden")))
+		})
+
+		It("Should deny a retry policy on a Deployment-mode Eva", func() {
+			obj.Spec.Mode = geofrontv1alpha1.EvaModeDeployment
+			obj.Spec.RetryPolicy = &geofrontv1alpha1.EvaRetryPolicy{MaxAttempts: 3}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.retryPolicy")))
+		})
+
+		It("Should deny a maximum backoff shorter than the backoff", func() {
+			obj.Spec.RetryPolicy = &geofrontv1alpha1.EvaRetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     &metav1.Duration{Duration: time.Minute},
+				MaxBackoff:  &metav1.Duration{Duration: time.Second},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.retryPolicy.maxBackoff")))
+		})
+
+		It("Should deny pod failure rules naming another container", func() {
+			obj.Spec.PodFailurePolicy = &batchv1.PodFailurePolicy{Rules: []batchv1.PodFailurePolicyRule{{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: ptr.To("sidecar"),
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+					Values:        []int32{2},
+				},
+			}}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("spec.podFailurePolicy.rules[0].onExitCodes.containerName")))
+		})
+
+		It("Should deny Job settings on a Deployment-mode Eva", func() {
+			obj.Spec.Mode = geofrontv1alpha1.EvaModeDeployment
+			obj.Spec.ActiveDeadlineSeconds = ptr.To(int64(600))
+			obj.Spec.Completions = ptr.To(int32(3))
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.activeDeadlineSeconds")))
+			Expect(err).To(MatchError(ContainSubstring("spec.completions")))
+		})
+
+		DescribeTable("completions",
+			func(completions *int32, mode batchv1.CompletionMode, policy *batchv1.SuccessPolicy, errPath string) {
+				obj.Spec.Completions = completions
+				obj.Spec.CompletionMode = mode
+				obj.Spec.SuccessPolicy = policy
+				_, err := validator.ValidateCreate(ctx, obj)
+				if errPath == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(ContainSubstring(errPath)))
+				}
+			},
+			Entry("an Indexed Job with a success policy", ptr.To(int32(5)), batchv1.IndexedCompletion,
+				&batchv1.SuccessPolicy{Rules: []batchv1.SuccessPolicyRule{{SucceededIndexes: ptr.To("0,2-4"), SucceededCount: ptr.To(int32(3))}}}, ""),
+			Entry("an Indexed Job without completions", nil, batchv1.IndexedCompletion, nil, "spec.completions: Required value"),
+			Entry("a success policy without indexes", ptr.To(int32(5)), batchv1.NonIndexedCompletion,
+				&batchv1.SuccessPolicy{Rules: []batchv1.SuccessPolicyRule{{SucceededCount: ptr.To(int32(1))}}}, "spec.successPolicy: Forbidden"),
+			Entry("an index beyond completions", ptr.To(int32(3)), batchv1.IndexedCompletion,
+				&batchv1.SuccessPolicy{Rules: []batchv1.SuccessPolicyRule{{SucceededIndexes: ptr.To("1-3")}}},
+				"spec.successPolicy.rules[0].succeededIndexes"),
+			Entry("indexes out of order", ptr.To(int32(5)), batchv1.IndexedCompletion,
+				&batchv1.SuccessPolicy{Rules: []batchv1.SuccessPolicyRule{{SucceededIndexes: ptr.To("3,1")}}},
+				"spec.successPolicy.rules[0].succeededIndexes"),
+			Entry("a count above the indexes of the rule", ptr.To(int32(5)), batchv1.IndexedCompletion,
+				&batchv1.SuccessPolicy{Rules: []batchv1.SuccessPolicyRule{{SucceededIndexes: ptr.To("0-1"), SucceededCount: ptr.To(int32(3))}}},
+				"spec.successPolicy.rules[0].succeededCount"),
+		)
+
+		It("Should leave backoffLimit unset for an Eva retrying per index", func() {
+			obj.Spec.CompletionMode = batchv1.IndexedCompletion
+			obj.Spec.Completions = ptr.To(int32(3))
+			obj.Spec.BackoffLimitPerIndex = ptr.To(int32(2))
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.BackoffLimit).To(BeNil())
+		})
+
+		DescribeTable("schedules",
+			func(mode geofrontv1alpha1.EvaMode, schedule *geofrontv1alpha1.EvaSchedule, errPath string) {
+				obj.Spec.Mode = mode
+				obj.Spec.Schedule = schedule
+				_, err := validator.ValidateCreate(ctx, obj)
+				if errPath == "" {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(ContainSubstring(errPath)))
+				}
+			},
+			Entry("cron fields with a time zone", geofrontv1alpha1.EvaModeCronJob,
+				&geofrontv1alpha1.EvaSchedule{Cron: "*/15 3 * * MON-FRI", TimeZone: ptr.To("Asia/Tokyo")}, ""),
+			Entry("a descriptor", geofrontv1alpha1.EvaModeCronJob, &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}, ""),
+			Entry("missing in CronJob mode", geofrontv1alpha1.EvaModeCronJob, nil, "spec.schedule"),
+			Entry("set in Job mode", geofrontv1alpha1.EvaModeJob, &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}, "spec.schedule"),
+			Entry("too few fields", geofrontv1alpha1.EvaModeCronJob, &geofrontv1alpha1.EvaSchedule{Cron: "0 3 * *"}, "spec.schedule.cron"),
+			Entry("an inline time zone", geofrontv1alpha1.EvaModeCronJob,
+				&geofrontv1alpha1.EvaSchedule{Cron: "CRON_TZ=UTC 0 3 * * *"}, "spec.schedule.cron"),
+			Entry("an unknown time zone", geofrontv1alpha1.EvaModeCronJob,
+				&geofrontv1alpha1.EvaSchedule{Cron: "0 3 * * *", TimeZone: ptr.To("Tokyo-3")}, "spec.schedule.timeZone"),
+		)
+
+		It("Should admit spec changes of a scheduled Eva between runs", func() {
+			oldObj.Spec.Mode = geofrontv1alpha1.EvaModeCronJob
+			oldObj.Spec.Schedule = &geofrontv1alpha1.EvaSchedule{Cron: "@daily"}
+			oldObj.Status.Phase = geofrontv1alpha1.EvaPhaseRunning
+			obj = oldObj.DeepCopy()
+			obj.Spec.Image = "registry.nerv.com:5000/geofront/eva:02"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny image changes while a run is active", func() {
+			oldObj.Status.Phase = geofrontv1alpha1.EvaPhaseRunning
+			obj.Spec.Image = "registry.nerv.com:5000/geofront/eva:02"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(MatchError(ContainSubstring("Forbidden")))
+		})
+
+		It("Should admit image changes while a run is active with the Replace strategy", func() {
+			oldObj.Status.Phase = geofrontv1alpha1.EvaPhaseRunning
+			obj.Spec.Image = "registry.nerv.com:5000/geofront/eva:02"
+			obj.Spec.UpdateStrategy = geofrontv1alpha1.EvaUpdateStrategyReplace
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny shrinking the storage of an Eva", func() {
+			oldObj.Spec.Storage = &geofrontv1alpha1.EvaStorage{Size: resource.MustParse("2Gi"), MountPath: "/data"}
+			obj.Spec.Storage = &geofrontv1alpha1.EvaStorage{Size: resource.MustParse("1Gi"), MountPath: "/data"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(MatchError(ContainSubstring("spec.storage.size")))
+		})
+
+		It("Should not re-validate unchanged fields on update", func() {
+			oldObj.Spec.Color = "beige"
+			obj.Spec.Color = "beige"
+			obj.Spec.Pilot = "Shinji Ikari"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+})
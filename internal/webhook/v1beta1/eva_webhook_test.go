@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	geofrontv1alpha1 "github.com/dayaliuzzo/Smooth-Operator/api/v1alpha1"
+	geofrontv1beta1 "github.com/dayaliuzzo/Smooth-Operator/api/v1beta1"
+)
+
+var _ = Describe("Eva Conversion", func() {
+	var alpha *geofrontv1alpha1.Eva
+
+	BeforeEach(func() {
+		alpha = &geofrontv1alpha1.Eva{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "unit-01",
+				Namespace:   "default",
+				Annotations: map[string]string{"nerv.com/owner": "ikari"},
+			},
+			Spec: geofrontv1alpha1.EvaSpec{
+				Image:              "registry.nerv.com/eva:01",
+				Foo:                ptr.To("bar"),
+				Paused:             true,
+				ImagePullSecret:    "nerv-registry",
+				Color:              "purple",
+				ResolveImageDigest: true,
+				ImagePullSecrets: []geofrontv1alpha1.EvaImagePullSecret{
+					{Name: "mirror-registry"}, {Name: "nerv-registry", Shared: true},
+				},
+				Pilot:      "Shinji",
+				Command:    []string{"sync", "--ratio=400"},
+				Args:       []string{"--verbose"},
+				Env:        []corev1.EnvVar{{Name: "PILOT", Value: "Shinji"}},
+				WorkingDir: "/srv",
+				Probes: &geofrontv1alpha1.EvaProbes{Startup: &corev1.Probe{
+					ProbeHandler:     corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"ready"}}},
+					FailureThreshold: 30,
+				}},
+				Sidecars: []corev1.Container{{Name: "log-shipper", Image: "fluent/fluent-bit:3.0"}},
+				Volumes: []corev1.Volume{{
+					Name:         "cache",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+				Storage: &geofrontv1alpha1.EvaStorage{
+					Size:          resource.MustParse("1Gi"),
+					MountPath:     "/data",
+					ReclaimPolicy: geofrontv1alpha1.EvaStorageReclaimPolicyRetain,
+				},
+				Mode:                    geofrontv1alpha1.EvaModeDeployment,
+				Replicas:                ptr.To(int32(2)),
+				Port:                    8080,
+				UpdateStrategy:          geofrontv1alpha1.EvaUpdateStrategyReplace,
+				DeletionPolicy:          geofrontv1alpha1.EvaDeletionPolicyRetain,
+				DeletionTimeout:         &metav1.Duration{Duration: time.Minute},
+				BackoffLimit:            ptr.To(int32(3)),
+				ActiveDeadlineSeconds:   ptr.To(int64(600)),
+				TTLSecondsAfterFinished: ptr.To(int32(300)),
+				Completions:             ptr.To(int32(4)),
+				CompletionMode:          batchv1.IndexedCompletion,
+				BackoffLimitPerIndex:    ptr.To(int32(1)),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				},
+				Scheduling: geofrontv1alpha1.EvaSchedulingSpec{
+					NodeSelector:      map[string]string{"nerv.com/pool": "cage-07"},
+					Tolerations:       []corev1.Toleration{{Key: "nerv.com/eva", Operator: corev1.TolerationOpExists}},
+					PriorityClassName: "eva-critical",
+				},
+				SecurityContext: &geofrontv1alpha1.EvaSecurityContext{
+					Pod: &corev1.PodSecurityContext{RunAsUser: ptr.To(int64(1000))},
+				},
+				ServiceAccount: &geofrontv1alpha1.EvaServiceAccount{Create: true},
+				Permissions: []rbacv1.PolicyRule{{
+					APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"},
+				}},
+			},
+			Status: geofrontv1alpha1.EvaStatus{
+				ObservedGeneration: 4,
+				Phase:              geofrontv1alpha1.EvaPhasePaused,
+				SpecHash:           "abc123",
+				JobGeneration:      3,
+				ResolvedImage:      "registry.nerv.com/eva:01@sha256:0123",
+				JobRef:             &corev1.ObjectReference{Kind: "Job", Name: "unit-01-job"},
+				Attempts:           2,
+				Progress:           "3/4 completed",
+				CompletedIndexes:   "0-2",
+				RecentRuns: []geofrontv1alpha1.EvaRunSummary{{
+					JobName: "unit-01-cronjob-29000000",
+					Phase:   geofrontv1alpha1.EvaPhaseSucceeded,
+				}},
+				Containers: []geofrontv1alpha1.EvaContainerStatus{{
+					Name:     "unit-01-container",
+					Type:     geofrontv1alpha1.EvaContainerTypeMain,
+					State:    "Terminated",
+					ExitCode: ptr.To(int32(137)),
+					Reason:   "OOMKilled",
+				}},
+				Conditions: []metav1.Condition{{
+					Type:   string(geofrontv1alpha1.EvaConditionPaused),
+					Status: metav1.ConditionTrue,
+					Reason: "Paused",
+				}},
+			},
+		}
+	})
+
+	It("Should group container, scheduling and exposure settings in v1beta1", func() {
+		beta := &geofrontv1beta1.Eva{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+
+		Expect(beta.Spec.Container.Image).To(Equal("registry.nerv.com/eva:01"))
+		Expect(beta.Spec.Container.Command).To(Equal([]string{"sync", "--ratio=400"}))
+		Expect(beta.Spec.Container.Args).To(Equal([]string{"--verbose"}))
+		Expect(beta.Spec.Container.Probes.Startup.FailureThreshold).To(Equal(int32(30)))
+		Expect(beta.Spec.Container.Env).To(HaveLen(1))
+		Expect(beta.Spec.Container.ImagePullSecret).To(Equal("nerv-registry"))
+		Expect(beta.Spec.Container.ImagePullSecrets).To(HaveLen(2))
+		Expect(beta.Spec.Container.ResolveImageDigest).To(BeTrue())
+		Expect(beta.Spec.Container.Resources.Requests.Cpu().String()).To(Equal("500m"))
+		Expect(beta.Spec.Scheduling.Replicas).To(Equal(ptr.To(int32(2))))
+		Expect(beta.Spec.Scheduling.Paused).To(BeTrue())
+		Expect(beta.Spec.Scheduling.BackoffLimit).To(Equal(ptr.To(int32(3))))
+		Expect(beta.Spec.Scheduling.ActiveDeadlineSeconds).To(Equal(ptr.To(int64(600))))
+		Expect(beta.Spec.Scheduling.TTLSecondsAfterFinished).To(Equal(ptr.To(int32(300))))
+		Expect(beta.Spec.Scheduling.CompletionMode).To(Equal(batchv1.IndexedCompletion))
+		Expect(beta.Spec.Scheduling.NodeSelector).To(HaveKeyWithValue("nerv.com/pool", "cage-07"))
+		Expect(beta.Spec.Scheduling.PriorityClassName).To(Equal("eva-critical"))
+		Expect(beta.Spec.Exposure.Port).To(Equal(int32(8080)))
+		Expect(beta.Spec.Mode).To(Equal(geofrontv1beta1.EvaModeDeployment))
+		Expect(beta.Status.Phase).To(Equal(geofrontv1beta1.EvaPhasePaused))
+		Expect(beta.Status.Conditions).To(HaveLen(1))
+	})
+
+	It("Should round-trip v1alpha1 through v1beta1 without loss", func() {
+		beta := &geofrontv1beta1.Eva{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+		Expect(beta.Annotations).To(HaveKeyWithValue("geofront.nerv.com/v1alpha1-foo", "bar"))
+
+		back := &geofrontv1alpha1.Eva{}
+		Expect(back.ConvertFrom(beta)).To(Succeed())
+		Expect(back).To(Equal(alpha))
+	})
+
+	It("Should leave no annotation behind when foo is unset", func() {
+		alpha.Spec.Foo = nil
+		alpha.Annotations = nil
+
+		beta := &geofrontv1beta1.Eva{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+		Expect(beta.Annotations).To(BeEmpty())
+
+		back := &geofrontv1alpha1.Eva{}
+		Expect(back.ConvertFrom(beta)).To(Succeed())
+		Expect(back).To(Equal(alpha))
+	})
+})